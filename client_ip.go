@@ -0,0 +1,100 @@
+package tgin
+
+import (
+	"net"
+	"strings"
+)
+
+var defaultRemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+// SetTrustedProxies sets the networks whose requests may carry the client
+// address in headers. Entries can be CIDRs or single IP addresses. Nil means
+// no proxy is trusted and ClientIP always returns the peer address.
+func (e *Engine) SetTrustedProxies(proxies []string) error {
+	cidrs := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return &net.ParseError{Type: "IP address", Text: proxy}
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	e.trustedCIDRs = cidrs
+	return nil
+}
+
+func (e *Engine) isTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range e.trustedCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Context) RemoteIP() string {
+	ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		return ""
+	}
+	return ip
+}
+
+// ClientIP returns the client address. Platform and remote IP headers are
+// only consulted when the request comes from a trusted proxy.
+func (c *Context) ClientIP() string {
+	remoteIP := c.RemoteIP()
+	e := c.engine
+	if e == nil || !e.isTrustedProxy(net.ParseIP(remoteIP)) {
+		return remoteIP
+	}
+	for _, header := range e.PlatformHeaders {
+		if ip := strings.TrimSpace(c.GetHeader(header)); net.ParseIP(ip) != nil {
+			return ip
+		}
+	}
+	headers := e.RemoteIPHeaders
+	if headers == nil {
+		headers = defaultRemoteIPHeaders
+	}
+	for _, header := range headers {
+		if ip, valid := e.validateHeader(c.GetHeader(header)); valid {
+			return ip
+		}
+	}
+	return remoteIP
+}
+
+// validateHeader walks the address list from the nearest hop and returns the
+// first address which is not a trusted proxy.
+func (e *Engine) validateHeader(header string) (string, bool) {
+	if header == "" {
+		return "", false
+	}
+	items := strings.Split(header, ",")
+	for i := len(items) - 1; i >= 0; i-- {
+		ipStr := strings.TrimSpace(items[i])
+		ip := net.ParseIP(ipStr)
+		if ip == nil {
+			return "", false
+		}
+		if i == 0 || !e.isTrustedProxy(ip) {
+			return ipStr, true
+		}
+	}
+	return "", false
+}
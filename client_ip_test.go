@@ -0,0 +1,71 @@
+package tgin
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func clientIPRequest(e *Engine, remoteAddr string, headers map[string]string) string {
+	ip := ""
+	e.Get("/ip", func(c *Context) {
+		ip = c.ClientIP()
+	})
+	req := httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	return ip
+}
+
+func TestRemoteIP(t *testing.T) {
+	req := getRequest("/", "")
+	req.RemoteAddr = "[::1]:1234"
+	ctx := createTestContext(req)
+	assertEqual(t, "::1", ctx.RemoteIP())
+	req.RemoteAddr = "invalid"
+	assertEqual(t, "", ctx.RemoteIP())
+}
+
+func TestClientIPWithoutTrustedProxies(t *testing.T) {
+	e := New()
+	ip := clientIPRequest(e, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "1.2.3.4"})
+	assertEqual(t, "10.0.0.1", ip, "Header should be ignored from untrusted peer")
+}
+
+func TestClientIPWithTrustedProxies(t *testing.T) {
+	e := New()
+	assertNil(t, e.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}))
+
+	ip := clientIPRequest(e, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "8.8.8.8, 1.2.3.4, 192.168.1.1"})
+	assertEqual(t, "1.2.3.4", ip, "Should skip trusted hops from the right")
+
+	ip = clientIPRequest(e, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.5, 10.0.0.6"})
+	assertEqual(t, "10.0.0.5", ip, "Should return leftmost when all hops trusted")
+
+	ip = clientIPRequest(e, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "garbage", "X-Real-IP": "5.6.7.8"})
+	assertEqual(t, "5.6.7.8", ip, "Should fallback to X-Real-IP")
+
+	ip = clientIPRequest(e, "192.168.1.1:1234", nil)
+	assertEqual(t, "192.168.1.1", ip)
+
+	ip = clientIPRequest(e, "172.16.0.1:1234", map[string]string{"X-Real-IP": "5.6.7.8"})
+	assertEqual(t, "172.16.0.1", ip)
+}
+
+func TestClientIPPlatformHeaders(t *testing.T) {
+	e := New()
+	e.PlatformHeaders = []string{"CF-Connecting-IP"}
+	assertNil(t, e.SetTrustedProxies([]string{"10.0.0.1"}))
+	ip := clientIPRequest(e, "10.0.0.1:1234", map[string]string{"CF-Connecting-IP": "9.9.9.9", "X-Forwarded-For": "1.2.3.4"})
+	assertEqual(t, "9.9.9.9", ip)
+	ip = clientIPRequest(e, "10.0.0.2:1234", map[string]string{"CF-Connecting-IP": "9.9.9.9"})
+	assertEqual(t, "10.0.0.2", ip)
+}
+
+func TestSetTrustedProxiesInvalid(t *testing.T) {
+	e := New()
+	assertNotNil(t, e.SetTrustedProxies([]string{"not-an-ip"}))
+	assertNotNil(t, e.SetTrustedProxies([]string{"10.0.0.0/99"}))
+}
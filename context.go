@@ -3,23 +3,34 @@ package tgin
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
-const formMaxMemory = 32 << 20 // 32 MB
+const defaultMultipartMemory = 32 << 20 // 32 MB
 
 type Context struct {
 	Method      string
 	Request     *http.Request
 	Writer      http.ResponseWriter
+	Errors      errorMsgs
 	aborted     bool
 	served      bool
 	values      map[string]interface{}
 	middlewares []RouteHandler
 	index       int
 	mux         *http.ServeMux
+	engine      *Engine
+	fullPath    string
+	sameSite    http.SameSite
+	queryCache  url.Values
+	formParsed  bool
+	formErr     error
 }
 
 func newContext(w http.ResponseWriter, r *http.Request) *Context {
@@ -35,25 +46,24 @@ func newContext(w http.ResponseWriter, r *http.Request) *Context {
 	}
 }
 
-func (c *Context) json(code int, val interface{}, indented bool) {
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	if indented {
-		enc.SetIndent("", "    ")
-	}
-	err := enc.Encode(val)
-	if err != nil {
-		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
-		return
-	}
+func (c *Context) render(code int, contentType string, buf *bytes.Buffer) {
 	w := c.Writer
 	hdr := w.Header()
-	hdr.Set("Content-Type", "application/json; charset=utf-8")
+	hdr.Set("Content-Type", contentType)
 	hdr.Set("Content-Length", fmt.Sprintf("%d", buf.Len()))
 	w.WriteHeader(code)
 	buf.WriteTo(w)
 }
 
+func (c *Context) json(code int, val interface{}, indented bool) {
+	buf, err := encodeJSON(val, indented, true)
+	if err != nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
+		return
+	}
+	c.render(code, "application/json; charset=utf-8", buf)
+}
+
 func (c *Context) BindJSON(obj interface{}) error {
 	dec := json.NewDecoder(c.Request.Body)
 	return dec.Decode(obj)
@@ -67,6 +77,30 @@ func (c *Context) IndentedJSON(code int, val interface{}) {
 	c.json(code, val, true)
 }
 
+func (c *Context) XML(code int, val interface{}) {
+	buf := bytes.NewBuffer(nil)
+	err := xml.NewEncoder(buf).Encode(val)
+	if err != nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
+		return
+	}
+	c.render(code, "application/xml; charset=utf-8", buf)
+}
+
+func (c *Context) HTML(code int, name string, data interface{}) {
+	if c.engine == nil || c.engine.htmlRender == nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", ErrNoHTMLTemplate))
+		return
+	}
+	var buf bytes.Buffer
+	err := c.engine.htmlRender.RenderHTML(&buf, name, data)
+	if err != nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
+		return
+	}
+	c.render(code, "text/html; charset=utf-8", &buf)
+}
+
 func (c *Context) Text(code int, message string) {
 	w := c.Writer
 	hdr := w.Header()
@@ -81,20 +115,97 @@ func (c *Context) String(code int, format string, values ...interface{}) {
 	c.Text(code, body)
 }
 
+func (c *Context) Data(code int, contentType string, data []byte) {
+	c.render(code, contentType, bytes.NewBuffer(data))
+}
+
+func (c *Context) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) {
+	w := c.Writer
+	hdr := w.Header()
+	for key, value := range extraHeaders {
+		hdr.Set(key, value)
+	}
+	hdr.Set("Content-Type", contentType)
+	if contentLength >= 0 {
+		hdr.Set("Content-Length", fmt.Sprintf("%d", contentLength))
+	}
+	w.WriteHeader(code)
+	io.Copy(w, reader)
+}
+
+// Stream calls step until it returns false or the client goes away, flushing
+// the response after every step. It returns true if the client disconnected.
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	w := c.Writer
+	done := c.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+			keepOpen := step(w)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+func (c *Context) initQueryCache() {
+	if c.queryCache == nil {
+		if c.Request != nil && c.Request.URL != nil {
+			c.queryCache = c.Request.URL.Query()
+		} else {
+			c.queryCache = url.Values{}
+		}
+	}
+}
+
+func (c *Context) Query(key string) string {
+	ret, _ := c.GetQuery(key)
+	return ret
+}
+
+func (c *Context) DefaultQuery(key, defaultValue string) string {
+	if ret, have := c.GetQuery(key); have {
+		return ret
+	}
+	return defaultValue
+}
+
 func (c *Context) GetQuery(key string) (string, bool) {
-	ret := c.Request.URL.Query().Get(key)
-	if ret == "" {
-		return ret, false
+	vals, have := c.GetQueryArray(key)
+	if !have {
+		return "", false
 	}
-	return ret, true
+	return vals[0], true
+}
+
+func (c *Context) QueryArray(key string) []string {
+	vals, _ := c.GetQueryArray(key)
+	return vals
 }
 
 func (c *Context) GetQueryArray(key string) ([]string, bool) {
-	val := c.Request.URL.Query()[key]
-	if len(val) == 0 {
+	c.initQueryCache()
+	vals := c.queryCache[key]
+	if len(vals) == 0 {
 		return []string{}, false
 	}
-	return val, true
+	return vals, true
+}
+
+func (c *Context) QueryMap(key string) map[string]string {
+	ret, _ := c.GetQueryMap(key)
+	return ret
+}
+
+func (c *Context) GetQueryMap(key string) (map[string]string, bool) {
+	c.initQueryCache()
+	return getMapValues(c.queryCache, key)
 }
 
 func (c *Context) GetHeader(key string) string {
@@ -115,6 +226,12 @@ func (c *Context) AbortWithStatus(code int) {
 	c.Abort()
 }
 
+// FullPath returns the registered route pattern which matched the request,
+// e.g. "/user/". It is empty before routing or when no route matched.
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
 func (c *Context) Set(key string, obj interface{}) {
 	c.values[key] = obj
 }
@@ -136,28 +253,129 @@ func (c *Context) Redirect(code int, location string) {
 	http.Redirect(c.Writer, c.Request, location, code)
 }
 
+func (c *Context) maxMultipartMemory() int64 {
+	if c.engine == nil || c.engine.MaxMultipartMemory <= 0 {
+		return defaultMultipartMemory
+	}
+	return c.engine.MaxMultipartMemory
+}
+
+func (c *Context) parsePostForm() {
+	if c.formParsed {
+		return
+	}
+	c.formParsed = true
+	err := c.Request.ParseForm()
+	merr := c.Request.ParseMultipartForm(c.maxMultipartMemory())
+	if err == nil && merr != http.ErrNotMultipart {
+		err = merr
+	}
+	c.formErr = err
+}
+
+// PostFormError returns the error met while parsing a urlencoded or multipart
+// request body, so malformed bodies can be told apart from empty forms.
+func (c *Context) PostFormError() error {
+	c.parsePostForm()
+	return c.formErr
+}
+
 func (c *Context) PostForm(key string) string {
-	ret := c.PostFormArray(key)
-	if len(ret) == 0 {
-		return ""
+	ret, _ := c.GetPostForm(key)
+	return ret
+}
+
+func (c *Context) DefaultPostForm(key, defaultValue string) string {
+	if ret, have := c.GetPostForm(key); have {
+		return ret
 	}
-	return ret[0]
+	return defaultValue
 }
 
-func (c *Context) PostFormArray(key string) []string {
-	if c.Request.Form == nil {
-		c.Request.ParseMultipartForm(formMaxMemory)
+func (c *Context) GetPostForm(key string) (string, bool) {
+	vals, have := c.GetPostFormArray(key)
+	if !have {
+		return "", false
 	}
+	return vals[0], true
+}
+
+func (c *Context) PostFormArray(key string) []string {
+	vals, _ := c.GetPostFormArray(key)
+	return vals
+}
+
+func (c *Context) GetPostFormArray(key string) ([]string, bool) {
+	c.parsePostForm()
 	vals := c.Request.Form[key]
 	if len(vals) == 0 {
-		return []string{}
+		return []string{}, false
 	}
-	return vals
+	return vals, true
+}
+
+func (c *Context) PostFormMap(key string) map[string]string {
+	ret, _ := c.GetPostFormMap(key)
+	return ret
+}
+
+func (c *Context) GetPostFormMap(key string) (map[string]string, bool) {
+	c.parsePostForm()
+	return getMapValues(c.Request.Form, key)
+}
+
+// getMapValues collects values sent as key[name]=value into a map.
+func getMapValues(values map[string][]string, key string) (map[string]string, bool) {
+	ret := make(map[string]string)
+	have := false
+	for k, v := range values {
+		if i := strings.IndexByte(k, '['); i >= 1 && k[:i] == key {
+			if j := strings.IndexByte(k[i+1:], ']'); j >= 1 {
+				have = true
+				ret[k[i+1:][:j]] = v[0]
+			}
+		}
+	}
+	return ret, have
 }
 
 func (c *Context) FormFile(key string) (*multipart.FileHeader, error) {
-	_, header, err := c.Request.FormFile(key)
-	return header, err
+	if c.Request.MultipartForm == nil {
+		if err := c.Request.ParseMultipartForm(c.maxMultipartMemory()); err != nil {
+			return nil, err
+		}
+	}
+	f, header, err := c.Request.FormFile(key)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return header, nil
+}
+
+func (c *Context) MultipartForm() (*multipart.Form, error) {
+	err := c.Request.ParseMultipartForm(c.maxMultipartMemory())
+	return c.Request.MultipartForm, err
+}
+
+// ResponseWriter returns c.Writer with its status and size accessors. The
+// writer of a Context made outside the router is wrapped on first use.
+func (c *Context) ResponseWriter() ResponseWriter {
+	if rw, ok := c.Writer.(ResponseWriter); ok {
+		return rw
+	}
+	rw, ww := wrapResponseWriter(c.Writer)
+	ww.ctx = c
+	c.Writer = rw
+	return rw.(ResponseWriter)
+}
+
+// OnWriteHeader registers fn to be called right before the response status
+// line is sent, which is the last chance to modify response headers.
+func (c *Context) OnWriteHeader(fn func()) {
+	if ww, ok := unwrapResponseWriter(c.Writer); ok && !ww.written {
+		ww.headerHandler = append(ww.headerHandler, fn)
+	}
 }
 
 func (c *Context) Next() {
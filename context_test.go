@@ -2,11 +2,14 @@ package tgin
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 )
 
@@ -152,3 +155,156 @@ func TestOutputRedirect(t *testing.T) {
 	assertEqual(t, 302, resp.StatusCode, "Status code not correct")
 	assertEqual(t, "/login", resp.Header.Get("Location"), "Location header not correct")
 }
+
+func TestOutputData(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	ctx.Data(200, "image/png", []byte("PNG"))
+	resp := ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, "image/png", resp.Header.Get("Content-Type"), "Content-Type not correct")
+	assertEqual(t, "3", resp.Header.Get("Content-Length"), "Content-Length not correct")
+	assertBody(t, resp, "PNG")
+}
+
+func TestOutputDataFromReader(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	headers := map[string]string{"Content-Disposition": `attachment; filename="data.bin"`}
+	ctx.DataFromReader(200, 7, "application/octet-stream", bytes.NewBufferString("content"), headers)
+	resp := ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, "application/octet-stream", resp.Header.Get("Content-Type"), "Content-Type not correct")
+	assertEqual(t, "7", resp.Header.Get("Content-Length"), "Content-Length not correct")
+	assertEqual(t, `attachment; filename="data.bin"`, resp.Header.Get("Content-Disposition"))
+	assertBody(t, resp, "content")
+}
+
+func TestOutputStream(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	steps := 0
+	clientGone := ctx.Stream(func(w io.Writer) bool {
+		steps++
+		fmt.Fprintf(w, "step %d\n", steps)
+		return steps < 3
+	})
+	rec := ctx.Writer.(*httptest.ResponseRecorder)
+	assertFalse(t, clientGone, "Client should not gone")
+	assertEqual(t, 3, steps, "Steps not correct")
+	assertTrue(t, rec.Flushed, "Response not flushed")
+	assertBody(t, rec.Result(), "step 1\nstep 2\nstep 3\n")
+}
+
+func TestOutputStreamClientGone(t *testing.T) {
+	cctx, cancel := context.WithCancel(context.Background())
+	req := getRequest("/", "").WithContext(cctx)
+	ctx := createTestContext(req)
+	steps := 0
+	clientGone := ctx.Stream(func(w io.Writer) bool {
+		steps++
+		if steps == 2 {
+			cancel()
+		}
+		return true
+	})
+	assertTrue(t, clientGone, "Client should gone")
+	assertEqual(t, 2, steps, "Steps not correct")
+}
+
+func TestGetPostForm(t *testing.T) {
+	req := postRequest("/?q=query", "a=1&empty=&m[x]=1&m[y]=2")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	ctx := createTestContext(req)
+	val, have := ctx.GetPostForm("empty")
+	assertTrue(t, have, "Form should have key empty")
+	assertEqual(t, "", val)
+	val, have = ctx.GetPostForm("q")
+	assertTrue(t, have, "Query value should in post form")
+	assertEqual(t, "query", val)
+	_, have = ctx.GetPostFormArray("missing")
+	assertFalse(t, have, "Form should not have key missing")
+	assertEqual(t, "1", ctx.DefaultPostForm("a", "0"))
+	assertEqual(t, "", ctx.DefaultPostForm("empty", "0"))
+	assertEqual(t, "0", ctx.DefaultPostForm("missing", "0"))
+	vals, have := ctx.GetPostFormMap("m")
+	assertTrue(t, have, "Form should have map m")
+	assertEqual(t, map[string]string{"x": "1", "y": "2"}, vals)
+	assertNil(t, ctx.PostFormError())
+}
+
+func TestPostFormMultipart(t *testing.T) {
+	body := bytes.NewBuffer(nil)
+	mpw := multipart.NewWriter(body)
+	mpw.WriteField("name", "tgin")
+	mpw.Close()
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Add("Content-Type", mpw.FormDataContentType())
+	ctx := createTestContext(req)
+	val, have := ctx.GetPostForm("name")
+	assertTrue(t, have, "Form should have key name")
+	assertEqual(t, "tgin", val)
+	assertNil(t, ctx.PostFormError())
+}
+
+func TestPostFormError(t *testing.T) {
+	req := postRequest("/", "a=%zz")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	ctx := createTestContext(req)
+	assertEqual(t, "", ctx.PostForm("a"))
+	assertNotNil(t, ctx.PostFormError())
+
+	req = postRequest("/", "--broken\r\n")
+	req.Header.Add("Content-Type", "multipart/form-data")
+	ctx = createTestContext(req)
+	assertEqual(t, []string{}, ctx.PostFormArray("a"))
+	assertNotNil(t, ctx.PostFormError())
+}
+
+func TestMaxMultipartMemory(t *testing.T) {
+	e := New()
+	e.MaxMultipartMemory = 1
+	e.Post("/", func(c *Context) {
+		fh, err := c.FormFile("file")
+		assertNil(t, err)
+		f, err := fh.Open()
+		assertNil(t, err)
+		defer f.Close()
+		_, onDisk := f.(*os.File)
+		assertTrue(t, onDisk, "File larger than MaxMultipartMemory should be stored in a temp file")
+		c.String(200, "%d", fh.Size)
+	})
+	body := bytes.NewBuffer(nil)
+	mpw := multipart.NewWriter(body)
+	fw, _ := mpw.CreateFormFile("file", "upload.txt")
+	fw.Write([]byte("content"))
+	mpw.Close()
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Add("Content-Type", mpw.FormDataContentType())
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	assertEqual(t, "7", w.Body.String())
+}
+
+func TestGetQueryPresence(t *testing.T) {
+	req := getRequest("/test?flag=&a=1&ids[a]=1&ids[b]=2&ids=3", "")
+	ctx := createTestContext(req)
+	val, have := ctx.GetQuery("flag")
+	assertTrue(t, have, "Query should have key flag")
+	assertEqual(t, "", val)
+	_, have = ctx.GetQuery("missing")
+	assertFalse(t, have, "Query should not have key missing")
+	assertEqual(t, "1", ctx.Query("a"))
+	assertEqual(t, "", ctx.Query("missing"))
+	assertEqual(t, "", ctx.DefaultQuery("flag", "default"))
+	assertEqual(t, "default", ctx.DefaultQuery("missing", "default"))
+	assertEqual(t, []string{}, ctx.QueryArray("missing"))
+	vals, have := ctx.GetQueryMap("ids")
+	assertTrue(t, have, "Query should have map ids")
+	assertEqual(t, map[string]string{"a": "1", "b": "2"}, vals)
+	_, have = ctx.GetQueryMap("a")
+	assertFalse(t, have, "Query should not have map a")
+}
+
+func TestQueryCache(t *testing.T) {
+	req := getRequest("/test?a=1", "")
+	ctx := createTestContext(req)
+	assertEqual(t, "1", ctx.Query("a"))
+	req.URL.RawQuery = "a=2"
+	assertEqual(t, "1", ctx.Query("a"), "Query should be parsed once")
+}
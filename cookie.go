@@ -0,0 +1,223 @@
+package tgin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrNoCookieSecret   = errors.New("cookie secret not set")
+	ErrInvalidCookie    = errors.New("invalid cookie value")
+	ErrCookieAuthFailed = errors.New("cookie authentication failed")
+)
+
+var cookieEncoding = base64.RawURLEncoding
+
+type cookieKey struct {
+	signKey []byte
+	aead    cipher.AEAD
+}
+
+// CookieCodec signs and encrypts cookie values. The first secret is used to
+// produce new values while all secrets are accepted when reading, so secrets
+// can be rotated by prepending a new one.
+type CookieCodec struct {
+	keys []cookieKey
+}
+
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+func NewCookieCodec(secrets ...[]byte) *CookieCodec {
+	keys := make([]cookieKey, 0, len(secrets))
+	for _, secret := range secrets {
+		block, err := aes.NewCipher(deriveKey(secret, "tgin cookie encryption"))
+		if err != nil {
+			panic(err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(err)
+		}
+		keys = append(keys, cookieKey{
+			signKey: deriveKey(secret, "tgin cookie signing"),
+			aead:    aead,
+		})
+	}
+	return &CookieCodec{keys: keys}
+}
+
+func (k cookieKey) sign(name, value string) []byte {
+	mac := hmac.New(sha256.New, k.signKey)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func (cc *CookieCodec) Sign(name, value string) (string, error) {
+	if len(cc.keys) == 0 {
+		return "", ErrNoCookieSecret
+	}
+	sig := cc.keys[0].sign(name, value)
+	return cookieEncoding.EncodeToString([]byte(value)) + "." + cookieEncoding.EncodeToString(sig), nil
+}
+
+func (cc *CookieCodec) Verify(name, signed string) (string, error) {
+	if len(cc.keys) == 0 {
+		return "", ErrNoCookieSecret
+	}
+	dot := strings.LastIndex(signed, ".")
+	if dot < 0 {
+		return "", ErrInvalidCookie
+	}
+	value, err := cookieEncoding.DecodeString(signed[:dot])
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	sig, err := cookieEncoding.DecodeString(signed[dot+1:])
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range cc.keys {
+		if hmac.Equal(sig, key.sign(name, string(value))) {
+			return string(value), nil
+		}
+	}
+	return "", ErrCookieAuthFailed
+}
+
+func (cc *CookieCodec) Encrypt(name, value string) (string, error) {
+	if len(cc.keys) == 0 {
+		return "", ErrNoCookieSecret
+	}
+	aead := cc.keys[0].aead
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	data := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return cookieEncoding.EncodeToString(data), nil
+}
+
+func (cc *CookieCodec) Decrypt(name, encrypted string) (string, error) {
+	if len(cc.keys) == 0 {
+		return "", ErrNoCookieSecret
+	}
+	data, err := cookieEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range cc.keys {
+		nonceSize := key.aead.NonceSize()
+		if len(data) < nonceSize {
+			return "", ErrInvalidCookie
+		}
+		value, err := key.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(name))
+		if err == nil {
+			return string(value), nil
+		}
+	}
+	return "", ErrCookieAuthFailed
+}
+
+func (e *Engine) SetCookieSecrets(secrets ...[]byte) {
+	e.cookieCodec = NewCookieCodec(secrets...)
+}
+
+func (c *Context) cookieCodec() (*CookieCodec, error) {
+	if c.engine == nil || c.engine.cookieCodec == nil {
+		return nil, ErrNoCookieSecret
+	}
+	return c.engine.cookieCodec, nil
+}
+
+func (c *Context) SetSameSite(sameSite http.SameSite) {
+	c.sameSite = sameSite
+}
+
+func (c *Context) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
+	if path == "" {
+		path = "/"
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     path,
+		Domain:   domain,
+		SameSite: c.sameSite,
+		Secure:   secure,
+		HttpOnly: httpOnly,
+	})
+}
+
+func (c *Context) Cookie(name string) (string, error) {
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return url.QueryUnescape(cookie.Value)
+}
+
+func (c *Context) SetSignedCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) error {
+	codec, err := c.cookieCodec()
+	if err != nil {
+		return err
+	}
+	signed, err := codec.Sign(name, value)
+	if err != nil {
+		return err
+	}
+	c.SetCookie(name, signed, maxAge, path, domain, secure, httpOnly)
+	return nil
+}
+
+func (c *Context) SignedCookie(name string) (string, error) {
+	codec, err := c.cookieCodec()
+	if err != nil {
+		return "", err
+	}
+	signed, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return codec.Verify(name, signed)
+}
+
+func (c *Context) SetEncryptedCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) error {
+	codec, err := c.cookieCodec()
+	if err != nil {
+		return err
+	}
+	encrypted, err := codec.Encrypt(name, value)
+	if err != nil {
+		return err
+	}
+	c.SetCookie(name, encrypted, maxAge, path, domain, secure, httpOnly)
+	return nil
+}
+
+func (c *Context) EncryptedCookie(name string) (string, error) {
+	codec, err := c.cookieCodec()
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return codec.Decrypt(name, encrypted)
+}
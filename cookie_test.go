@@ -0,0 +1,123 @@
+package tgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func cookieRequest(e *Engine, path string, cookies []*http.Cookie) *http.Response {
+	req := httptest.NewRequest("GET", path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w.Result()
+}
+
+func TestSetCookie(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie("user", "a b&c", 3600, "", "example.com", true, true)
+	resp := ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, "user=a+b%26c; Path=/; Domain=example.com; Max-Age=3600; HttpOnly; Secure; SameSite=Lax", resp.Header.Get("Set-Cookie"))
+}
+
+func TestGetCookie(t *testing.T) {
+	req := getRequest("/", "")
+	req.AddCookie(&http.Cookie{Name: "user", Value: "a+b%26c"})
+	ctx := createTestContext(req)
+	val, err := ctx.Cookie("user")
+	assertNil(t, err)
+	assertEqual(t, "a b&c", val)
+	_, err = ctx.Cookie("missing")
+	assertEqual(t, http.ErrNoCookie, err)
+}
+
+func TestSignedCookie(t *testing.T) {
+	e := New()
+	e.SetCookieSecrets([]byte("secret"))
+	e.Get("/set", func(c *Context) {
+		assertNil(t, c.SetSignedCookie("uid", "42", 0, "/", "", false, true))
+	})
+	e.Get("/get", func(c *Context) {
+		val, err := c.SignedCookie("uid")
+		if err != nil {
+			c.String(403, err.Error())
+			return
+		}
+		c.String(200, val)
+	})
+	resp := cookieRequest(e, "/set", nil)
+	cookies := resp.Cookies()
+	assertEqual(t, 1, len(cookies))
+
+	resp = cookieRequest(e, "/get", cookies)
+	assertBody(t, resp, "42")
+
+	tampered := *cookies[0]
+	tampered.Value = "NDM" + tampered.Value[strings.Index(tampered.Value, "."):]
+	resp = cookieRequest(e, "/get", []*http.Cookie{&tampered})
+	assertEqual(t, 403, resp.StatusCode)
+	assertBody(t, resp, ErrCookieAuthFailed.Error())
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	e := New()
+	e.SetCookieSecrets([]byte("secret"))
+	e.Get("/set", func(c *Context) {
+		assertNil(t, c.SetEncryptedCookie("state", "hidden value", 0, "/", "", false, true))
+	})
+	e.Get("/get", func(c *Context) {
+		val, err := c.EncryptedCookie("state")
+		if err != nil {
+			c.String(403, err.Error())
+			return
+		}
+		c.String(200, val)
+	})
+	resp := cookieRequest(e, "/set", nil)
+	cookies := resp.Cookies()
+	assertEqual(t, 1, len(cookies))
+	assertFalse(t, strings.Contains(cookies[0].Value, "hidden"), "Cookie value should be encrypted")
+
+	resp = cookieRequest(e, "/get", cookies)
+	assertBody(t, resp, "hidden value")
+
+	renamed := *cookies[0]
+	renamed.Name = "other"
+	e.Get("/other", func(c *Context) {
+		_, err := c.EncryptedCookie("other")
+		assertEqual(t, ErrCookieAuthFailed, err)
+	})
+	cookieRequest(e, "/other", []*http.Cookie{&renamed})
+}
+
+func TestCookieCodecKeyRotation(t *testing.T) {
+	oldCodec := NewCookieCodec([]byte("old"))
+	newCodec := NewCookieCodec([]byte("new"), []byte("old"))
+
+	signed, _ := oldCodec.Sign("name", "value")
+	val, err := newCodec.Verify("name", signed)
+	assertNil(t, err)
+	assertEqual(t, "value", val)
+
+	encrypted, _ := oldCodec.Encrypt("name", "value")
+	val, err = newCodec.Decrypt("name", encrypted)
+	assertNil(t, err)
+	assertEqual(t, "value", val)
+
+	signed, _ = newCodec.Sign("name", "value")
+	_, err = oldCodec.Verify("name", signed)
+	assertEqual(t, ErrCookieAuthFailed, err)
+}
+
+func TestCookieWithoutSecret(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	err := ctx.SetSignedCookie("uid", "42", 0, "/", "", false, true)
+	assertEqual(t, ErrNoCookieSecret, err)
+	_, err = ctx.EncryptedCookie("uid")
+	assertEqual(t, ErrNoCookieSecret, err)
+}
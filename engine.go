@@ -1,17 +1,30 @@
 package tgin
 
 import (
+	"net"
 	"net/http"
 )
 
 type Engine struct {
 	*RouteGroup
+	MaxMultipartMemory int64
+	RemoteIPHeaders    []string
+	PlatformHeaders    []string
+	trustedCIDRs       []*net.IPNet
+	htmlRender         HTMLRenderer
+	secureJSONPrefix   string
+	cookieCodec        *CookieCodec
+	errorHandler       ErrorHandler
 }
 
 func New() *Engine {
-	return &Engine{
-		RouteGroup: NewRouteGroup(),
+	e := &Engine{
+		RouteGroup:         NewRouteGroup(),
+		MaxMultipartMemory: defaultMultipartMemory,
+		RemoteIPHeaders:    defaultRemoteIPHeaders,
 	}
+	e.RouteGroup.engine = e
+	return e
 }
 
 func Default() *Engine {
@@ -0,0 +1,159 @@
+package tgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+type ErrorType uint64
+
+const (
+	ErrorTypeBind    ErrorType = 1 << 63
+	ErrorTypeRender  ErrorType = 1 << 62
+	ErrorTypePrivate ErrorType = 1 << 0
+	ErrorTypePublic  ErrorType = 1 << 1
+	ErrorTypeAny     ErrorType = 1<<64 - 1
+)
+
+type Error struct {
+	Err  error
+	Type ErrorType
+	Meta interface{}
+}
+
+type errorMsgs []*Error
+
+var _ error = (*Error)(nil)
+
+func (e *Error) SetType(flags ErrorType) *Error {
+	e.Type = flags
+	return e
+}
+
+func (e *Error) SetMeta(data interface{}) *Error {
+	e.Meta = data
+	return e
+}
+
+func (e *Error) JSON() interface{} {
+	jsonData := H{}
+	if e.Meta != nil {
+		value := reflect.ValueOf(e.Meta)
+		switch value.Kind() {
+		case reflect.Struct:
+			return e.Meta
+		case reflect.Map:
+			for _, key := range value.MapKeys() {
+				jsonData[fmt.Sprint(key.Interface())] = value.MapIndex(key).Interface()
+			}
+		default:
+			jsonData["meta"] = e.Meta
+		}
+	}
+	if _, have := jsonData["error"]; !have {
+		jsonData["error"] = e.Error()
+	}
+	return jsonData
+}
+
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.JSON())
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) IsType(flags ErrorType) bool {
+	return (e.Type & flags) > 0
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (a errorMsgs) ByType(typ ErrorType) errorMsgs {
+	if len(a) == 0 {
+		return nil
+	}
+	if typ == ErrorTypeAny {
+		return a
+	}
+	var result errorMsgs
+	for _, msg := range a {
+		if msg.IsType(typ) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+func (a errorMsgs) Last() *Error {
+	if length := len(a); length > 0 {
+		return a[length-1]
+	}
+	return nil
+}
+
+func (a errorMsgs) Errors() []string {
+	if len(a) == 0 {
+		return nil
+	}
+	errorStrings := make([]string, len(a))
+	for i, err := range a {
+		errorStrings[i] = err.Error()
+	}
+	return errorStrings
+}
+
+func (a errorMsgs) JSON() interface{} {
+	switch length := len(a); length {
+	case 0:
+		return nil
+	case 1:
+		return a.Last().JSON()
+	default:
+		jsonData := make([]interface{}, length)
+		for i, err := range a {
+			jsonData[i] = err.JSON()
+		}
+		return jsonData
+	}
+}
+
+func (a errorMsgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.JSON())
+}
+
+func (a errorMsgs) String() string {
+	if len(a) == 0 {
+		return ""
+	}
+	var buffer bytes.Buffer
+	for i, msg := range a {
+		fmt.Fprintf(&buffer, "Error #%02d: %s\n", i+1, msg.Err)
+		if msg.Meta != nil {
+			fmt.Fprintf(&buffer, "     Meta: %v\n", msg.Meta)
+		}
+	}
+	return buffer.String()
+}
+
+// Error records err on the context so middlewares can report it after the
+// handlers return. The returned *Error can be used to set its type and meta.
+func (c *Context) Error(err error) *Error {
+	if err == nil {
+		panic("err is nil")
+	}
+	parsedError, ok := err.(*Error)
+	if !ok {
+		parsedError = &Error{
+			Err:  err,
+			Type: ErrorTypePrivate,
+		}
+	}
+	c.Errors = append(c.Errors, parsedError)
+	return parsedError
+}
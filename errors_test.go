@@ -0,0 +1,68 @@
+package tgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestContextError(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	assertTrue(t, ctx.Errors.Last() == nil, "Last should be nil without errors")
+
+	ctx.Error(errors.New("first"))
+	ctx.Error(errors.New("second")).SetType(ErrorTypePublic).SetMeta(H{"field": "name"})
+	custom := &Error{Err: errors.New("bind"), Type: ErrorTypeBind}
+	assertTrue(t, ctx.Error(custom) == custom, "Error should keep *Error")
+
+	assertEqual(t, 3, len(ctx.Errors))
+	assertEqual(t, "bind", ctx.Errors.Last().Error())
+	assertEqual(t, []string{"first"}, ctx.Errors.ByType(ErrorTypePrivate).Errors())
+	assertEqual(t, []string{"second"}, ctx.Errors.ByType(ErrorTypePublic).Errors())
+	assertEqual(t, 3, len(ctx.Errors.ByType(ErrorTypeAny)))
+	assertEqual(t, "Error #01: first\nError #02: second\n     Meta: map[field:name]\nError #03: bind\n", ctx.Errors.String())
+	assertTrue(t, errors.Is(ctx.Errors[0], ctx.Errors[0].Err), "Error should unwrap")
+}
+
+func TestContextErrorNil(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	defer func() {
+		assertNotNil(t, recover(), "Error(nil) should panic")
+	}()
+	ctx.Error(nil)
+}
+
+func TestErrorJSON(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	ctx.Error(errors.New("first")).SetMeta(H{"status": "bad"})
+	data, _ := json.Marshal(ctx.Errors)
+	assertEqual(t, `{"error":"first","status":"bad"}`, string(data))
+
+	ctx.Error(errors.New("second")).SetMeta("detail")
+	data, _ = json.Marshal(ctx.Errors)
+	assertEqual(t, `[{"error":"first","status":"bad"},{"error":"second","meta":"detail"}]`, string(data))
+}
+
+func TestLoggerMiddlewareWithErrors(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(ReleaseMode)
+	buf := bytes.NewBuffer(nil)
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	r := NewRouteGroup()
+	r.Use(LoggerMiddleware)
+	r.Get("/", func(c *Context) {
+		c.Error(errors.New("database unavailable"))
+		c.Error(errors.New("public message")).SetType(ErrorTypePublic)
+		c.String(503, "Unavailable")
+	})
+	processRequest(r, "GET", "/")
+	output := buf.String()
+	assertTrue(t, strings.Contains(output, "Error #01: database unavailable"), output)
+	assertFalse(t, strings.Contains(output, "public message"), output)
+}
@@ -0,0 +1,51 @@
+package tgin
+
+import (
+	"net/http"
+)
+
+type HandlerE func(c *Context) error
+
+type ErrorHandler func(c *Context, err error)
+
+// E adapts an error returning handler to a RouteHandler. Returned errors are
+// passed to the engine error handler.
+func E(handler HandlerE) RouteHandler {
+	return func(c *Context) {
+		err := handler(c)
+		if err == nil {
+			return
+		}
+		if c.engine != nil && c.engine.errorHandler != nil {
+			c.engine.errorHandler(c, err)
+			return
+		}
+		DefaultErrorHandler(c, err)
+	}
+}
+
+func (e *Engine) SetErrorHandler(handler ErrorHandler) {
+	e.errorHandler = handler
+}
+
+// DefaultErrorHandler records the error and answers 500 if the handler has
+// not written a response yet.
+func DefaultErrorHandler(c *Context, err error) {
+	c.Error(err)
+	if ww, ok := unwrapResponseWriter(c.Writer); ok && ww.Written() {
+		return
+	}
+	c.Text(http.StatusInternalServerError, "500 internal server error\n")
+}
+
+// ProblemErrorHandler renders returned errors as problem details using the
+// registry, see ProblemMiddleware.
+func ProblemErrorHandler(registry *ProblemRegistry) ErrorHandler {
+	return func(c *Context, err error) {
+		perr := c.Error(err)
+		if ww, ok := unwrapResponseWriter(c.Writer); ok && ww.Written() {
+			return
+		}
+		c.Problem(registry.problemFor(perr))
+	}
+}
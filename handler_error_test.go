@@ -0,0 +1,56 @@
+package tgin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHandlerE(t *testing.T) {
+	e := New()
+	e.Get("/ok", E(func(c *Context) error {
+		c.String(200, "OK")
+		return nil
+	}))
+	e.Get("/fail", E(func(c *Context) error {
+		return errors.New("failed")
+	}))
+	resp := processRequest(e.RouteGroup, "GET", "/ok")
+	assertEqual(t, 200, resp.StatusCode)
+	assertBody(t, resp, "OK")
+
+	resp = processRequest(e.RouteGroup, "GET", "/fail")
+	assertEqual(t, 500, resp.StatusCode)
+	assertBody(t, resp, "500 internal server error\n")
+}
+
+func TestHandlerEWithErrorHandler(t *testing.T) {
+	e := New()
+	var recorded errorMsgs
+	e.Use(func(c *Context) {
+		c.Next()
+		recorded = c.Errors
+	})
+	e.SetErrorHandler(func(c *Context, err error) {
+		c.Error(err)
+		c.JSON(400, H{"error": err.Error()})
+	})
+	e.Post("/", E(func(c *Context) error {
+		return errors.New("bad input")
+	}))
+	resp := processRequest(e.RouteGroup, "POST", "/")
+	assertEqual(t, 400, resp.StatusCode)
+	assertBody(t, resp, "{\"error\":\"bad input\"}\n")
+	assertEqual(t, 1, len(recorded))
+}
+
+func TestHandlerEWithProblemErrorHandler(t *testing.T) {
+	e := New()
+	e.SetErrorHandler(ProblemErrorHandler(NewProblemRegistry().Register(errNotFound, Problem{Status: 404})))
+	e.Get("/", E(func(c *Context) error {
+		return errNotFound
+	}))
+	resp := processRequest(e.RouteGroup, "GET", "/")
+	assertEqual(t, 404, resp.StatusCode)
+	data := decodeProblem(t, resp)
+	assertEqual(t, "Not Found", data["title"])
+}
@@ -0,0 +1,19 @@
+package tgin
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrNoHTMLTemplate = errors.New("html template not loaded")
+
+// HTMLRenderer renders named HTML templates for c.HTML. The html subpackage
+// provides the html/template based implementation, so binaries which never
+// render HTML do not link the template packages.
+type HTMLRenderer interface {
+	RenderHTML(w io.Writer, name string, data interface{}) error
+}
+
+func (e *Engine) SetHTMLRenderer(renderer HTMLRenderer) {
+	e.htmlRender = renderer
+}
@@ -0,0 +1,142 @@
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/blacktear23/tgin"
+)
+
+type loader func() (*templates, error)
+
+type templates struct {
+	templ   *template.Template
+	layouts map[string]*template.Template
+}
+
+// Render renders html/template templates. In debug mode templates loaded from
+// files are parsed again on every render.
+type Render struct {
+	sync.RWMutex
+	funcMap   template.FuncMap
+	templates *templates
+	loader    loader
+}
+
+// New creates a Render and registers it on e, so c.HTML renders through it.
+func New(e *tgin.Engine) *Render {
+	r := &Render{}
+	e.SetHTMLRenderer(r)
+	return r
+}
+
+func (r *Render) SetFuncMap(funcMap template.FuncMap) {
+	r.Lock()
+	r.funcMap = funcMap
+	r.Unlock()
+}
+
+func (r *Render) SetHTMLTemplate(templ *template.Template) {
+	r.Lock()
+	r.templates = &templates{templ: templ}
+	r.loader = nil
+	r.Unlock()
+}
+
+func (r *Render) LoadHTMLGlob(pattern string) {
+	r.load(func() (*templates, error) {
+		templ, err := template.New("").Funcs(r.funcMap).ParseGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &templates{templ: templ}, nil
+	})
+}
+
+func (r *Render) LoadHTMLFiles(files ...string) {
+	r.load(func() (*templates, error) {
+		templ, err := template.New("").Funcs(r.funcMap).ParseFiles(files...)
+		if err != nil {
+			return nil, err
+		}
+		return &templates{templ: templ}, nil
+	})
+}
+
+// LoadHTMLLayouts parses every page matched by pagesGlob together with all
+// layouts matched by layoutsGlob. Pages are rendered by their file name and
+// executed through the first layout, so each page can define its own blocks.
+func (r *Render) LoadHTMLLayouts(layoutsGlob, pagesGlob string) {
+	r.load(func() (*templates, error) {
+		layoutFiles, err := filepath.Glob(layoutsGlob)
+		if err != nil {
+			return nil, err
+		}
+		if len(layoutFiles) == 0 {
+			return nil, fmt.Errorf("html/template: pattern matches no files: %#q", layoutsGlob)
+		}
+		pages, err := filepath.Glob(pagesGlob)
+		if err != nil {
+			return nil, err
+		}
+		base, err := template.New(filepath.Base(layoutFiles[0])).Funcs(r.funcMap).ParseFiles(layoutFiles...)
+		if err != nil {
+			return nil, err
+		}
+		layouts := make(map[string]*template.Template, len(pages))
+		for _, page := range pages {
+			templ, err := base.Clone()
+			if err != nil {
+				return nil, err
+			}
+			templ, err = templ.ParseFiles(page)
+			if err != nil {
+				return nil, err
+			}
+			layouts[filepath.Base(page)] = templ
+		}
+		return &templates{layouts: layouts}, nil
+	})
+}
+
+func (r *Render) load(loader loader) {
+	r.Lock()
+	defer r.Unlock()
+	templates, err := loader()
+	if err != nil {
+		panic(err)
+	}
+	r.templates = templates
+	r.loader = loader
+}
+
+func (r *Render) RenderHTML(w io.Writer, name string, data interface{}) error {
+	if tgin.IsDebugging() {
+		r.Lock()
+		if r.loader != nil {
+			templates, err := r.loader()
+			if err != nil {
+				r.Unlock()
+				return err
+			}
+			r.templates = templates
+		}
+		r.Unlock()
+	}
+	r.RLock()
+	templates := r.templates
+	r.RUnlock()
+	if templates == nil {
+		return tgin.ErrNoHTMLTemplate
+	}
+	if templ, have := templates.layouts[name]; have {
+		return templ.Execute(w, data)
+	}
+	if templates.templ == nil {
+		return fmt.Errorf("html/template: %q is undefined", name)
+	}
+	return templates.templ.ExecuteTemplate(w, name, data)
+}
@@ -0,0 +1,147 @@
+package html
+
+import (
+	"html/template"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blacktear23/tgin"
+)
+
+func get(e *tgin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func assertCode(t *testing.T, w *httptest.ResponseRecorder, code int) {
+	if w.Code != code {
+		t.Fatalf("Expect status %d but got: %d", code, w.Code)
+	}
+}
+
+func assertBody(t *testing.T, w *httptest.ResponseRecorder, body string) {
+	if w.Body.String() != body {
+		t.Fatalf("Expect body %q but got: %q", body, w.Body.String())
+	}
+}
+
+func writeTemplate(t *testing.T, dir, name, content string) string {
+	fpath := filepath.Join(dir, name)
+	err := ioutil.WriteFile(fpath, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Write template got error: %v", err)
+	}
+	return fpath
+}
+
+func createTemplateDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "tgin-html")
+	if err != nil {
+		t.Fatalf("Create temp dir got error: %v", err)
+	}
+	return dir
+}
+
+func TestHTMLLoadGlob(t *testing.T) {
+	dir := createTemplateDir(t)
+	defer os.RemoveAll(dir)
+	writeTemplate(t, dir, "hello.tmpl", "<p>Hello {{.name | upper}}</p>")
+
+	e := tgin.New()
+	r := New(e)
+	r.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+	r.LoadHTMLGlob(filepath.Join(dir, "*.tmpl"))
+	e.Get("/", func(c *tgin.Context) {
+		c.HTML(200, "hello.tmpl", tgin.H{"name": "<world>"})
+	})
+	w := get(e, "/")
+	assertCode(t, w, 200)
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("Unexpected content type: %s", ct)
+	}
+	assertBody(t, w, "<p>Hello &lt;WORLD&gt;</p>")
+}
+
+func TestHTMLLoadFilesAndMissingTemplate(t *testing.T) {
+	dir := createTemplateDir(t)
+	defer os.RemoveAll(dir)
+	fpath := writeTemplate(t, dir, "index.tmpl", "index")
+
+	e := tgin.New()
+	r := New(e)
+	r.LoadHTMLFiles(fpath)
+	e.Get("/", func(c *tgin.Context) {
+		c.HTML(200, "index.tmpl", nil)
+	})
+	e.Get("/missing", func(c *tgin.Context) {
+		c.HTML(200, "missing.tmpl", nil)
+	})
+	w := get(e, "/")
+	assertBody(t, w, "index")
+	w = get(e, "/missing")
+	assertCode(t, w, 500)
+}
+
+func TestHTMLSetTemplate(t *testing.T) {
+	e := tgin.New()
+	r := New(e)
+	r.SetHTMLTemplate(template.Must(template.New("page").Parse("{{.}}")))
+	e.Get("/", func(c *tgin.Context) {
+		c.HTML(201, "page", "content")
+	})
+	w := get(e, "/")
+	assertCode(t, w, 201)
+	assertBody(t, w, "content")
+}
+
+func TestHTMLLayouts(t *testing.T) {
+	dir := createTemplateDir(t)
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "layouts"), 0755)
+	os.Mkdir(filepath.Join(dir, "pages"), 0755)
+	writeTemplate(t, dir, "layouts/base.tmpl", `<body>{{template "content" .}}</body>`)
+	writeTemplate(t, dir, "pages/a.tmpl", `{{define "content"}}A {{.}}{{end}}`)
+	writeTemplate(t, dir, "pages/b.tmpl", `{{define "content"}}B {{.}}{{end}}`)
+
+	e := tgin.New()
+	r := New(e)
+	r.LoadHTMLLayouts(filepath.Join(dir, "layouts/*.tmpl"), filepath.Join(dir, "pages/*.tmpl"))
+	e.Get("/a", func(c *tgin.Context) {
+		c.HTML(200, "a.tmpl", "page")
+	})
+	e.Get("/b", func(c *tgin.Context) {
+		c.HTML(200, "b.tmpl", "page")
+	})
+	w := get(e, "/a")
+	assertBody(t, w, "<body>A page</body>")
+	w = get(e, "/b")
+	assertBody(t, w, "<body>B page</body>")
+}
+
+func TestHTMLReloadInDebugMode(t *testing.T) {
+	dir := createTemplateDir(t)
+	defer os.RemoveAll(dir)
+	writeTemplate(t, dir, "index.tmpl", "v1")
+
+	e := tgin.New()
+	r := New(e)
+	r.LoadHTMLGlob(filepath.Join(dir, "*.tmpl"))
+	e.Get("/", func(c *tgin.Context) {
+		c.HTML(200, "index.tmpl", nil)
+	})
+
+	defer tgin.SetMode(tgin.Mode())
+	tgin.SetMode(tgin.ReleaseMode)
+	writeTemplate(t, dir, "index.tmpl", "v2")
+	w := get(e, "/")
+	assertBody(t, w, "v1")
+
+	tgin.SetMode(tgin.DebugMode)
+	w = get(e, "/")
+	assertBody(t, w, "v2")
+}
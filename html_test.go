@@ -0,0 +1,52 @@
+package tgin
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+type stubHTMLRenderer struct{}
+
+func (stubHTMLRenderer) RenderHTML(w io.Writer, name string, data interface{}) error {
+	if name != "page" {
+		return fmt.Errorf("%q is undefined", name)
+	}
+	_, err := fmt.Fprintf(w, "<p>%v</p>", data)
+	return err
+}
+
+func TestHTMLRenderer(t *testing.T) {
+	e := New()
+	e.SetHTMLRenderer(stubHTMLRenderer{})
+	e.Get("/", func(c *Context) {
+		c.HTML(201, "page", "content")
+	})
+	e.Get("/missing", func(c *Context) {
+		c.HTML(200, "missing", nil)
+	})
+	resp := processRequest(e.RouteGroup, "GET", "/")
+	assertEqual(t, 201, resp.StatusCode)
+	assertEqual(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assertBody(t, resp, "<p>content</p>")
+	resp = processRequest(e.RouteGroup, "GET", "/missing")
+	assertEqual(t, 500, resp.StatusCode)
+}
+
+func TestHTMLWithoutRenderer(t *testing.T) {
+	e := New()
+	e.Get("/", func(c *Context) {
+		c.HTML(200, "index.tmpl", nil)
+	})
+	resp := processRequest(e.RouteGroup, "GET", "/")
+	assertEqual(t, 500, resp.StatusCode)
+}
+
+func TestHTMLWithoutEngine(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/", func(c *Context) {
+		c.HTML(200, "index.tmpl", nil)
+	})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, 500, resp.StatusCode)
+}
@@ -0,0 +1,117 @@
+package tgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+const DefaultSecureJSONPrefix = "while(1);"
+
+var jsonpCallbackRegexp = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`)
+
+func encodeJSON(val interface{}, indented bool, escapeHTML bool) (*bytes.Buffer, error) {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(escapeHTML)
+	if indented {
+		enc.SetIndent("", "    ")
+	}
+	err := enc.Encode(val)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (e *Engine) SecureJSONPrefix(prefix string) {
+	e.secureJSONPrefix = prefix
+}
+
+func (c *Context) secureJSONPrefix() string {
+	if c.engine == nil || c.engine.secureJSONPrefix == "" {
+		return DefaultSecureJSONPrefix
+	}
+	return c.engine.secureJSONPrefix
+}
+
+// JSONP wraps the JSON output into the function named by the callback query
+// parameter. Callbacks that are not plain JavaScript identifiers are rejected.
+func (c *Context) JSONP(code int, val interface{}) {
+	callback := c.Query("callback")
+	if callback == "" {
+		c.json(code, val, false)
+		return
+	}
+	if !jsonpCallbackRegexp.MatchString(callback) {
+		c.Text(400, "Invalid JSONP callback")
+		return
+	}
+	data, err := encodeJSON(val, false, true)
+	if err != nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("/**/")
+	buf.WriteString(callback)
+	buf.WriteByte('(')
+	buf.Write(bytes.TrimRight(data.Bytes(), "\n"))
+	buf.WriteString(");")
+	c.render(code, "application/javascript; charset=utf-8", buf)
+}
+
+// SecureJSON prefixes JSON arrays with an unparsable statement to prevent
+// JSON hijacking.
+func (c *Context) SecureJSON(code int, val interface{}) {
+	data, err := encodeJSON(val, false, true)
+	if err != nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
+		return
+	}
+	if bytes.HasPrefix(data.Bytes(), []byte("[")) {
+		buf := bytes.NewBufferString(c.secureJSONPrefix())
+		data.WriteTo(buf)
+		data = buf
+	}
+	c.render(code, "application/json; charset=utf-8", data)
+}
+
+// AsciiJSON escapes every non-ASCII character with \u sequences.
+func (c *Context) AsciiJSON(code int, val interface{}) {
+	data, err := encodeJSON(val, false, true)
+	if err != nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
+		return
+	}
+	c.render(code, "application/json", asciiEscape(data.Bytes()))
+}
+
+// PureJSON writes literal HTML characters instead of escaping them.
+func (c *Context) PureJSON(code int, val interface{}) {
+	data, err := encodeJSON(val, false, false)
+	if err != nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
+		return
+	}
+	c.render(code, "application/json; charset=utf-8", data)
+}
+
+func asciiEscape(data []byte) *bytes.Buffer {
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r < utf8.RuneSelf {
+			buf.WriteByte(data[0])
+		} else if r > 0xFFFF {
+			r -= 0x10000
+			fmt.Fprintf(buf, "\\u%04x\\u%04x", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+		} else {
+			fmt.Fprintf(buf, "\\u%04x", r)
+		}
+		data = data[size:]
+	}
+	return buf
+}
@@ -0,0 +1,64 @@
+package tgin
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOutputJSONP(t *testing.T) {
+	ctx := createTestContext(getRequest("/?callback=app.handle", ""))
+	ctx.JSONP(200, H{"key": "value"})
+	resp := ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, "application/javascript; charset=utf-8", resp.Header.Get("Content-Type"))
+	assertBody(t, resp, "/**/app.handle({\"key\":\"value\"});")
+
+	ctx = createTestContext(getRequest("/", ""))
+	ctx.JSONP(200, H{"key": "value"})
+	resp = ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	assertBody(t, resp, "{\"key\":\"value\"}\n")
+
+	ctx = createTestContext(getRequest("/?callback=alert(1)", ""))
+	ctx.JSONP(200, H{"key": "value"})
+	resp = ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, 400, resp.StatusCode)
+}
+
+func TestOutputSecureJSON(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	ctx.SecureJSON(200, []string{"a", "b"})
+	resp := ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertBody(t, resp, "while(1);[\"a\",\"b\"]\n")
+
+	ctx = createTestContext(getRequest("/", ""))
+	ctx.SecureJSON(200, H{"key": "value"})
+	resp = ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertBody(t, resp, "{\"key\":\"value\"}\n")
+}
+
+func TestOutputSecureJSONWithPrefix(t *testing.T) {
+	e := New()
+	e.SecureJSONPrefix(")]}',\n")
+	e.Get("/", func(c *Context) {
+		c.SecureJSON(200, []int{1, 2})
+	})
+	resp := processRequest(e.RouteGroup, "GET", "/")
+	assertEqual(t, "12", resp.Header.Get("Content-Length"), "Content-Length should include prefix")
+	assertBody(t, resp, ")]}',\n[1,2]\n")
+}
+
+func TestOutputAsciiJSON(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	ctx.AsciiJSON(200, H{"lang": "GO语言", "emoji": "😀", "tag": "<br>"})
+	resp := ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, "application/json", resp.Header.Get("Content-Type"))
+	assertBody(t, resp, "{\"emoji\":\"\\ud83d\\ude00\",\"lang\":\"GO\\u8bed\\u8a00\",\"tag\":\"\\u003cbr\\u003e\"}\n")
+}
+
+func TestOutputPureJSON(t *testing.T) {
+	ctx := createTestContext(getRequest("/", ""))
+	ctx.PureJSON(200, H{"html": "<b>Hello</b>"})
+	resp := ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	assertBody(t, resp, "{\"html\":\"<b>Hello</b>\"}\n")
+}
@@ -1,18 +1,228 @@
 package tgin
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
+type LogFormatterParams struct {
+	Request      *http.Request
+	TimeStamp    time.Time
+	StatusCode   int
+	Latency      time.Duration
+	ClientIP     string
+	Method       string
+	Path         string
+	RawQuery     string
+	BodySize     int
+	UserAgent    string
+	RequestID    string
+	ErrorMessage string
+	Keys         map[string]interface{}
+}
+
+type LogFormatter func(params LogFormatterParams) string
+
+type LoggerConfig struct {
+	// Output receives one formatted line per request. Nil logs through the
+	// standard log package, which adds its own prefix and timestamp, except in
+	// TestMode.
+	Output io.Writer
+
+	// Formatter formats a request line. Nil means DefaultLogFormatter.
+	Formatter LogFormatter
+
+	// SkipPaths lists URL paths which are not logged, e.g. health checks.
+	SkipPaths []string
+}
+
+var defaultLogger = LoggerWithConfig(LoggerConfig{})
+
 func LoggerMiddleware(c *Context) {
-	begin := time.Now()
-	c.Next()
-	ww, ok := c.Writer.(*ResponseWriterWrapper)
-	if !ok {
-		return
-	}
-	r := c.Request
-	processTime := time.Now().Sub(begin).String()
-	log.Printf("[Web] %d | %10s | %20s | %4s %s", ww.code, processTime, r.RemoteAddr, r.Method, r.URL.Path)
+	defaultLogger(c)
+}
+
+func LoggerWithFormatter(formatter LogFormatter) RouteHandler {
+	return LoggerWithConfig(LoggerConfig{Formatter: formatter})
+}
+
+func LoggerWithWriter(out io.Writer, skipPaths ...string) RouteHandler {
+	return LoggerWithConfig(LoggerConfig{Output: out, SkipPaths: skipPaths})
+}
+
+func LoggerWithConfig(conf LoggerConfig) RouteHandler {
+	formatter := conf.Formatter
+	if formatter == nil {
+		formatter = DefaultLogFormatter
+		if conf.Output == nil {
+			formatter = defaultLogLine
+		}
+	}
+	skip := skipPathSet(conf.SkipPaths)
+	return func(c *Context) {
+		begin := time.Now()
+		r := c.Request
+		path := r.URL.Path
+		rawQuery := r.URL.RawQuery
+		c.Next()
+		if skip[path] {
+			return
+		}
+		ww, ok := unwrapResponseWriter(c.Writer)
+		if !ok {
+			return
+		}
+		now := time.Now()
+		params := LogFormatterParams{
+			Request:      r,
+			TimeStamp:    now,
+			StatusCode:   ww.Status(),
+			Latency:      now.Sub(begin),
+			ClientIP:     c.ClientIP(),
+			Method:       r.Method,
+			Path:         path,
+			RawQuery:     rawQuery,
+			BodySize:     ww.Size(),
+			UserAgent:    r.UserAgent(),
+			RequestID:    c.RequestID(),
+			ErrorMessage: c.Errors.ByType(ErrorTypePrivate).String(),
+			Keys:         c.values,
+		}
+		if conf.Output != nil {
+			io.WriteString(conf.Output, formatter(params))
+		} else if Mode() != TestMode {
+			log.Print(formatter(params))
+		}
+	}
+}
+
+func skipPathSet(paths []string) map[string]bool {
+	skip := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		skip[path] = true
+	}
+	return skip
+}
+
+// DefaultLogFormatter formats the classic tgin log line.
+func DefaultLogFormatter(p LogFormatterParams) string {
+	return p.TimeStamp.Format("2006/01/02 15:04:05") + " " + defaultLogLine(p)
+}
+
+// defaultLogLine is DefaultLogFormatter without the timestamp, which the log
+// package adds itself.
+func defaultLogLine(p LogFormatterParams) string {
+	line := fmt.Sprintf("[Web] %d | %10s | %20s | %4s %s", p.StatusCode, p.Latency.String(), p.ClientIP, p.Method, p.Path)
+	if p.RequestID != "" {
+		line += " | " + p.RequestID
+	}
+	line += "\n"
+	if p.ErrorMessage != "" {
+		line += p.ErrorMessage
+	}
+	return line
+}
+
+func (p LogFormatterParams) requestURI() string {
+	if p.RawQuery == "" {
+		return p.Path
+	}
+	return p.Path + "?" + p.RawQuery
+}
+
+func dashIfEmpty(val string) string {
+	if val == "" {
+		return "-"
+	}
+	return val
+}
+
+// ApacheCombinedLogFormatter formats lines in the Apache combined log format.
+func ApacheCombinedLogFormatter(p LogFormatterParams) string {
+	user := "-"
+	if p.Request.URL.User != nil && p.Request.URL.User.Username() != "" {
+		user = p.Request.URL.User.Username()
+	} else if name, _, ok := p.Request.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	size := "-"
+	if p.BodySize > 0 {
+		size = strconv.Itoa(p.BodySize)
+	}
+	return fmt.Sprintf("%s - %s [%s] %q %d %s %q %q\n",
+		dashIfEmpty(p.ClientIP),
+		user,
+		p.TimeStamp.Format("02/Jan/2006:15:04:05 -0700"),
+		p.Method+" "+p.requestURI()+" "+p.Request.Proto,
+		p.StatusCode,
+		size,
+		dashIfEmpty(p.Request.Referer()),
+		dashIfEmpty(p.UserAgent),
+	)
+}
+
+// JSONLogFormatter formats every request as one JSON object per line.
+func JSONLogFormatter(p LogFormatterParams) string {
+	entry := map[string]interface{}{
+		"time":       p.TimeStamp.Format(time.RFC3339Nano),
+		"status":     p.StatusCode,
+		"latency_ms": float64(p.Latency) / float64(time.Millisecond),
+		"client_ip":  p.ClientIP,
+		"method":     p.Method,
+		"path":       p.Path,
+		"bytes":      p.BodySize,
+	}
+	if p.RawQuery != "" {
+		entry["query"] = p.RawQuery
+	}
+	if p.UserAgent != "" {
+		entry["user_agent"] = p.UserAgent
+	}
+	if p.RequestID != "" {
+		entry["request_id"] = p.RequestID
+	}
+	if p.ErrorMessage != "" {
+		entry["error"] = strings.TrimSpace(p.ErrorMessage)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Sprintf("{\"error\":%q}\n", err.Error())
+	}
+	return string(data) + "\n"
+}
+
+func logfmtValue(val string) string {
+	if val == "" || strings.ContainsAny(val, " =\"\t\r\n") {
+		return strconv.Quote(val)
+	}
+	return val
+}
+
+// LogfmtLogFormatter formats lines as logfmt key=value pairs.
+func LogfmtLogFormatter(p LogFormatterParams) string {
+	var b strings.Builder
+	b.WriteString("time=" + p.TimeStamp.Format(time.RFC3339))
+	b.WriteString(" status=" + strconv.Itoa(p.StatusCode))
+	b.WriteString(" latency=" + p.Latency.String())
+	b.WriteString(" client_ip=" + logfmtValue(p.ClientIP))
+	b.WriteString(" method=" + p.Method)
+	b.WriteString(" path=" + logfmtValue(p.requestURI()))
+	b.WriteString(" bytes=" + strconv.Itoa(p.BodySize))
+	if p.UserAgent != "" {
+		b.WriteString(" user_agent=" + logfmtValue(p.UserAgent))
+	}
+	if p.RequestID != "" {
+		b.WriteString(" request_id=" + logfmtValue(p.RequestID))
+	}
+	if p.ErrorMessage != "" {
+		b.WriteString(" error=" + logfmtValue(strings.TrimSpace(p.ErrorMessage)))
+	}
+	b.WriteString("\n")
+	return b.String()
 }
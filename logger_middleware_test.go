@@ -0,0 +1,131 @@
+package tgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoggerWithWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	r := NewRouteGroup()
+	r.Use(LoggerWithWriter(buf, "/health"))
+	r.Get("/hello", func(c *Context) {
+		c.String(200, "hello")
+	})
+	r.Get("/health", func(c *Context) {
+		c.String(200, "ok")
+	})
+	processRequest(r, "GET", "/hello")
+	line := buf.String()
+	assertTrue(t, strings.Contains(line, "[Web] 200 |"), line)
+	assertTrue(t, strings.Contains(line, "GET /hello"), line)
+
+	buf.Reset()
+	processRequest(r, "GET", "/health")
+	assertEqual(t, "", buf.String())
+}
+
+func TestLoggerFormatterParams(t *testing.T) {
+	var params LogFormatterParams
+	r := NewRouteGroup()
+	r.Use(LoggerWithConfig(LoggerConfig{
+		Output: bytes.NewBuffer(nil),
+		Formatter: func(p LogFormatterParams) string {
+			params = p
+			return ""
+		},
+	}))
+	r.Get("/hello", func(c *Context) {
+		c.Set("user", "alice")
+		c.Error(errors.New("db down"))
+		c.String(201, "hello")
+	})
+	req, _ := http.NewRequest("GET", "/hello?name=a", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("User-Agent", "tgin-test")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assertEqual(t, 201, params.StatusCode)
+	assertEqual(t, "GET", params.Method)
+	assertEqual(t, "/hello", params.Path)
+	assertEqual(t, "name=a", params.RawQuery)
+	assertEqual(t, 5, params.BodySize)
+	assertEqual(t, "10.0.0.1", params.ClientIP)
+	assertEqual(t, "tgin-test", params.UserAgent)
+	assertEqual(t, "Error #01: db down\n", params.ErrorMessage)
+	assertEqual(t, "alice", params.Keys["user"])
+}
+
+func testLogParams() LogFormatterParams {
+	req, _ := http.NewRequest("GET", "/items?page=2", nil)
+	req.Header.Set("Referer", "http://example.com/")
+	return LogFormatterParams{
+		Request:      req,
+		TimeStamp:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		StatusCode:   200,
+		Latency:      1500 * time.Microsecond,
+		ClientIP:     "10.0.0.1",
+		Method:       "GET",
+		Path:         "/items",
+		RawQuery:     "page=2",
+		BodySize:     42,
+		UserAgent:    "curl/7.0",
+		ErrorMessage: "Error #01: db down\n",
+	}
+}
+
+func TestApacheCombinedLogFormatter(t *testing.T) {
+	line := ApacheCombinedLogFormatter(testLogParams())
+	assertEqual(t, `10.0.0.1 - - [02/Jan/2020:03:04:05 +0000] "GET /items?page=2 HTTP/1.1" 200 42 "http://example.com/" "curl/7.0"`+"\n", line)
+}
+
+func TestJSONLogFormatter(t *testing.T) {
+	line := JSONLogFormatter(testLogParams())
+	assertTrue(t, strings.HasSuffix(line, "\n"))
+	var entry map[string]interface{}
+	assertNil(t, json.Unmarshal([]byte(line), &entry))
+	assertEqual(t, float64(200), entry["status"])
+	assertEqual(t, 1.5, entry["latency_ms"])
+	assertEqual(t, "/items", entry["path"])
+	assertEqual(t, "page=2", entry["query"])
+	assertEqual(t, float64(42), entry["bytes"])
+	assertEqual(t, "Error #01: db down", entry["error"])
+}
+
+func TestLogfmtLogFormatter(t *testing.T) {
+	line := LogfmtLogFormatter(testLogParams())
+	assertEqual(t, `time=2020-01-02T03:04:05Z status=200 latency=1.5ms client_ip=10.0.0.1 method=GET path="/items?page=2" bytes=42 user_agent=curl/7.0 error="Error #01: db down"`+"\n", line)
+}
+
+func TestLoggerDefaultOutputUsesLogPackage(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(ReleaseMode)
+	flags, prefix := log.Flags(), log.Prefix()
+	defer log.SetFlags(flags)
+	defer log.SetPrefix(prefix)
+	log.SetFlags(0)
+	log.SetPrefix("app: ")
+	r := NewRouteGroup()
+	r.Use(LoggerMiddleware)
+	r.Get("/hello", func(c *Context) {
+		c.String(200, "hello")
+	})
+	output := captureLog(func() {
+		processRequest(r, "GET", "/hello")
+	})
+	assertTrue(t, strings.HasPrefix(output, "app: [Web] 200 |"), output)
+	assertTrue(t, strings.HasSuffix(output, "GET /hello\n"), output)
+}
+
+func TestDefaultLogFormatter(t *testing.T) {
+	p := testLogParams()
+	p.ErrorMessage = ""
+	assertEqual(t, "2020/01/02 03:04:05 [Web] 200 |      1.5ms |             10.0.0.1 |  GET /items\n", DefaultLogFormatter(p))
+}
@@ -0,0 +1,218 @@
+package tgin
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const MIMEPrometheusText = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	DefaultSizeBuckets    = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+var knownMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "CONNECT": true, "OPTIONS": true, "TRACE": true,
+}
+
+type MetricsConfig struct {
+	// Namespace prefixes every metric name. Empty means "tgin".
+	Namespace string
+
+	// LatencyBuckets are the upper bounds in seconds of the latency
+	// histogram. Nil means DefaultLatencyBuckets.
+	LatencyBuckets []float64
+
+	// SizeBuckets are the upper bounds in bytes of the response size
+	// histogram. Nil means DefaultSizeBuckets.
+	SizeBuckets []float64
+}
+
+type metricLabels struct {
+	method string
+	route  string
+	status string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(buckets []float64, val float64) {
+	for i, bound := range buckets {
+		if val <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += val
+	h.count++
+}
+
+type requestMetrics struct {
+	count   uint64
+	latency histogram
+	size    histogram
+}
+
+// Metrics collects request metrics labelled by method, route pattern and
+// status. Requests which match no route share the "unmatched" route label so
+// scanned URLs cannot blow up the number of series.
+type Metrics struct {
+	namespace      string
+	latencyBuckets []float64
+	sizeBuckets    []float64
+	inFlight       int64
+	mu             sync.Mutex
+	requests       map[metricLabels]*requestMetrics
+}
+
+func NewMetrics(conf MetricsConfig) *Metrics {
+	m := &Metrics{
+		namespace:      conf.Namespace,
+		latencyBuckets: sortedBuckets(conf.LatencyBuckets, DefaultLatencyBuckets),
+		sizeBuckets:    sortedBuckets(conf.SizeBuckets, DefaultSizeBuckets),
+		requests:       map[metricLabels]*requestMetrics{},
+	}
+	if m.namespace == "" {
+		m.namespace = "tgin"
+	}
+	return m
+}
+
+func sortedBuckets(buckets, defaults []float64) []float64 {
+	if buckets == nil {
+		buckets = defaults
+	}
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+	return sorted
+}
+
+func (m *Metrics) Middleware() RouteHandler {
+	return func(c *Context) {
+		begin := time.Now()
+		atomic.AddInt64(&m.inFlight, 1)
+		defer atomic.AddInt64(&m.inFlight, -1)
+		c.Next()
+		ww, ok := unwrapResponseWriter(c.Writer)
+		if !ok {
+			return
+		}
+		method := c.Request.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.observe(metricLabels{method, route, strconv.Itoa(ww.Status())}, time.Since(begin), ww.Size())
+	}
+}
+
+func (m *Metrics) observe(labels metricLabels, latency time.Duration, size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rm, have := m.requests[labels]
+	if !have {
+		rm = &requestMetrics{
+			latency: histogram{counts: make([]uint64, len(m.latencyBuckets))},
+			size:    histogram{counts: make([]uint64, len(m.sizeBuckets))},
+		}
+		m.requests[labels] = rm
+	}
+	rm.count++
+	rm.latency.observe(m.latencyBuckets, latency.Seconds())
+	rm.size.observe(m.sizeBuckets, float64(size))
+}
+
+// MetricsHandler serves the metrics in the Prometheus text exposition format.
+func MetricsHandler(m *Metrics) RouteHandler {
+	return func(c *Context) {
+		c.render(http.StatusOK, MIMEPrometheusText, m.expose())
+	}
+}
+
+func (m *Metrics) expose() *bytes.Buffer {
+	buf := bytes.NewBuffer(nil)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels := make([]metricLabels, 0, len(m.requests))
+	for key := range m.requests {
+		labels = append(labels, key)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		a, b := labels[i], labels[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+
+	name := m.namespace + "_http_requests_total"
+	writeMetricHeader(buf, name, "counter", "Total number of HTTP requests.")
+	for _, key := range labels {
+		fmt.Fprintf(buf, "%s{%s} %d\n", name, key.format(), m.requests[key].count)
+	}
+
+	name = m.namespace + "_http_request_duration_seconds"
+	writeMetricHeader(buf, name, "histogram", "HTTP request latency in seconds.")
+	for _, key := range labels {
+		writeHistogram(buf, name, key.format(), m.latencyBuckets, &m.requests[key].latency)
+	}
+
+	name = m.namespace + "_http_response_size_bytes"
+	writeMetricHeader(buf, name, "histogram", "HTTP response body size in bytes.")
+	for _, key := range labels {
+		writeHistogram(buf, name, key.format(), m.sizeBuckets, &m.requests[key].size)
+	}
+
+	name = m.namespace + "_http_requests_in_flight"
+	writeMetricHeader(buf, name, "gauge", "Number of HTTP requests being served.")
+	fmt.Fprintf(buf, "%s %d\n", name, atomic.LoadInt64(&m.inFlight))
+	return buf
+}
+
+func writeMetricHeader(buf *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeHistogram(buf *bytes.Buffer, name, labels string, buckets []float64, h *histogram) {
+	var cumulative uint64
+	for i, bound := range buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(buf, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(bound), cumulative)
+	}
+	fmt.Fprintf(buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(buf, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+	fmt.Fprintf(buf, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+func formatFloat(val float64) string {
+	return strconv.FormatFloat(val, 'g', -1, 64)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (l metricLabels) format() string {
+	return fmt.Sprintf(`method="%s",route="%s",status="%s"`,
+		labelValueReplacer.Replace(l.method),
+		labelValueReplacer.Replace(l.route),
+		labelValueReplacer.Replace(l.status))
+}
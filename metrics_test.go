@@ -0,0 +1,73 @@
+package tgin
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	m := NewMetrics(MetricsConfig{LatencyBuckets: []float64{1, 0.1}, SizeBuckets: []float64{10}})
+	r := NewRouteGroup()
+	r.Use(m.Middleware())
+	r.Get("/users/", func(c *Context) {
+		c.String(200, "hello")
+	})
+	r.Get("/metrics", MetricsHandler(m))
+	processRequest(r, "GET", "/users/1")
+	processRequest(r, "GET", "/users/2")
+	processRequest(r, "POST", "/users/3")
+	processRequest(r, "GET", "/scan/a")
+	processRequest(r, "GET", "/scan/b")
+	processRequest(r, "BREW", "/users/1")
+
+	resp := processRequest(r, "GET", "/metrics")
+	assertEqual(t, 200, resp.StatusCode)
+	assertEqual(t, MIMEPrometheusText, resp.Header.Get("Content-Type"))
+	body := ReadBodyString(resp)
+	expects := []string{
+		"# TYPE tgin_http_requests_total counter\n",
+		`tgin_http_requests_total{method="GET",route="/users/",status="200"} 2` + "\n",
+		`tgin_http_requests_total{method="POST",route="/users/",status="404"} 1` + "\n",
+		`tgin_http_requests_total{method="OTHER",route="/users/",status="404"} 1` + "\n",
+		`tgin_http_requests_total{method="GET",route="unmatched",status="404"} 2` + "\n",
+		"# TYPE tgin_http_request_duration_seconds histogram\n",
+		`tgin_http_request_duration_seconds_bucket{method="GET",route="/users/",status="200",le="0.1"} 2` + "\n",
+		`tgin_http_request_duration_seconds_bucket{method="GET",route="/users/",status="200",le="+Inf"} 2` + "\n",
+		`tgin_http_request_duration_seconds_count{method="GET",route="/users/",status="200"} 2` + "\n",
+		`tgin_http_response_size_bytes_bucket{method="GET",route="/users/",status="200",le="10"} 2` + "\n",
+		`tgin_http_response_size_bytes_sum{method="GET",route="/users/",status="200"} 10` + "\n",
+		"# TYPE tgin_http_requests_in_flight gauge\ntgin_http_requests_in_flight 1\n",
+	}
+	for _, expect := range expects {
+		assertTrue(t, strings.Contains(body, expect), "Missing: "+expect+"\n"+body)
+	}
+	assertFalse(t, strings.Contains(body, "/scan/"), body)
+	assertTrue(t, strings.Index(body, `le="0.1"`) < strings.Index(body, `le="1"`), "Buckets should be sorted")
+}
+
+func TestMetricsHistogram(t *testing.T) {
+	m := NewMetrics(MetricsConfig{Namespace: "app", LatencyBuckets: []float64{0.1, 1}, SizeBuckets: []float64{100}})
+	labels := metricLabels{"GET", "/", "200"}
+	m.observe(labels, 50*time.Millisecond, 10)
+	m.observe(labels, 500*time.Millisecond, 1000)
+	m.observe(labels, 5*time.Second, 20)
+	body := m.expose().String()
+	expects := []string{
+		`app_http_request_duration_seconds_bucket{method="GET",route="/",status="200",le="0.1"} 1`,
+		`app_http_request_duration_seconds_bucket{method="GET",route="/",status="200",le="1"} 2`,
+		`app_http_request_duration_seconds_bucket{method="GET",route="/",status="200",le="+Inf"} 3`,
+		`app_http_request_duration_seconds_sum{method="GET",route="/",status="200"} 5.55`,
+		`app_http_response_size_bytes_bucket{method="GET",route="/",status="200",le="100"} 2`,
+		`app_http_response_size_bytes_sum{method="GET",route="/",status="200"} 1030`,
+		"app_http_requests_in_flight 0",
+	}
+	for _, expect := range expects {
+		assertTrue(t, strings.Contains(body, expect+"\n"), "Missing: "+expect+"\n"+body)
+	}
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	labels := metricLabels{"GET", "/a\"b\\c\n", "200"}
+	assertEqual(t, `method="GET",route="/a\"b\\c\n",status="200"`, labels.format())
+}
@@ -0,0 +1,61 @@
+package tgin
+
+import (
+	"log"
+	"os"
+	"sync/atomic"
+)
+
+// EnvTginMode is the environment variable which sets the initial mode.
+const EnvTginMode = "TGIN_MODE"
+
+const (
+	// DebugMode prints route registration and warnings, and re-parses HTML
+	// templates on every render.
+	DebugMode = "debug"
+	// ReleaseMode is silent apart from the request and recovery logs.
+	ReleaseMode = "release"
+	// TestMode also silences the request and recovery logs which write to
+	// the standard log package.
+	TestMode = "test"
+)
+
+// tginMode holds the mode string, so handlers may read it while SetMode runs.
+var tginMode atomic.Value
+
+func init() {
+	setModeFromEnv()
+}
+
+func setModeFromEnv() {
+	SetMode(os.Getenv(EnvTginMode))
+}
+
+// SetMode sets the framework mode. An empty value means ReleaseMode.
+func SetMode(value string) {
+	switch value {
+	case "":
+		tginMode.Store(ReleaseMode)
+	case DebugMode, ReleaseMode, TestMode:
+		tginMode.Store(value)
+	default:
+		panic("tgin mode unknown: " + value + " (available modes: debug, release, test)")
+	}
+}
+
+func Mode() string {
+	if mode, ok := tginMode.Load().(string); ok {
+		return mode
+	}
+	return ReleaseMode
+}
+
+func IsDebugging() bool {
+	return Mode() == DebugMode
+}
+
+func debugPrint(format string, values ...interface{}) {
+	if IsDebugging() {
+		log.Printf("[Web-debug] "+format, values...)
+	}
+}
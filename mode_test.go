@@ -0,0 +1,102 @@
+package tgin
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func captureLog(fn func()) string {
+	buf := bytes.NewBuffer(nil)
+	out := log.Writer()
+	log.SetOutput(buf)
+	defer log.SetOutput(out)
+	fn()
+	return buf.String()
+}
+
+func TestSetMode(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+	assertEqual(t, DebugMode, Mode())
+	assertTrue(t, IsDebugging())
+	SetMode(TestMode)
+	assertEqual(t, TestMode, Mode())
+	assertFalse(t, IsDebugging())
+	SetMode("")
+	assertEqual(t, ReleaseMode, Mode())
+
+	defer func() {
+		assertNotNil(t, recover())
+	}()
+	SetMode("unknown")
+}
+
+func TestSetModeConcurrent(t *testing.T) {
+	defer SetMode(Mode())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			IsDebugging()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		SetMode(DebugMode)
+		SetMode(ReleaseMode)
+	}
+	<-done
+}
+
+func TestModeFromEnv(t *testing.T) {
+	defer SetMode(Mode())
+	os.Setenv(EnvTginMode, TestMode)
+	defer os.Unsetenv(EnvTginMode)
+	setModeFromEnv()
+	assertEqual(t, TestMode, Mode())
+
+	os.Unsetenv(EnvTginMode)
+	setModeFromEnv()
+	assertEqual(t, ReleaseMode, Mode())
+}
+
+func TestDebugModePrintsRoutes(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+	output := captureLog(func() {
+		r := NewRouteGroup()
+		r.Group("/api").Post("/users", func(c *Context) {})
+	})
+	assertTrue(t, strings.Contains(output, "[Web-debug] POST    /api/users"), output)
+
+	SetMode(ReleaseMode)
+	output = captureLog(func() {
+		r := NewRouteGroup()
+		r.Get("/users", func(c *Context) {})
+	})
+	assertEqual(t, "", output)
+}
+
+func TestTestModeSilencesLogs(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(TestMode)
+	r := NewRouteGroup()
+	r.Use(LoggerMiddleware, RecoveryMiddleware)
+	r.Get("/panic", func(c *Context) {
+		panic("quiet")
+	})
+	output := captureLog(func() {
+		resp := processRequest(r, "GET", "/panic")
+		assertEqual(t, 500, resp.StatusCode)
+	})
+	assertEqual(t, "", output)
+
+	SetMode(ReleaseMode)
+	output = captureLog(func() {
+		processRequest(r, "GET", "/panic")
+	})
+	assertTrue(t, strings.Contains(output, "[Recovery] panic recovered: quiet"), output)
+	assertTrue(t, strings.Contains(output, "[Web] 500"), output)
+}
@@ -0,0 +1,144 @@
+package tgin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	MIMEJSON  = "application/json"
+	MIMEHTML  = "text/html"
+	MIMEXML   = "application/xml"
+	MIMEXML2  = "text/xml"
+	MIMEPlain = "text/plain"
+)
+
+type Negotiate struct {
+	Offered  []string
+	HTMLName string
+	HTMLData interface{}
+	JSONData interface{}
+	XMLData  interface{}
+	Data     interface{}
+}
+
+type acceptRange struct {
+	mimeType string
+	subType  string
+	quality  float64
+}
+
+func parseAccept(header string) []acceptRange {
+	ret := []acceptRange{}
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+		ar := acceptRange{quality: 1}
+		if slash := strings.Index(mediaType, "/"); slash >= 0 {
+			ar.mimeType, ar.subType = mediaType[:slash], mediaType[slash+1:]
+		} else if mediaType == "*" {
+			ar.mimeType, ar.subType = "*", "*"
+		} else {
+			continue
+		}
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			ar.quality = q
+		}
+		ret = append(ret, ar)
+	}
+	return ret
+}
+
+// match returns the specificity of the range for the given media type, or -1
+// when it does not match.
+func (ar acceptRange) match(mediaType string) int {
+	mediaType = strings.ToLower(mediaType)
+	if semi := strings.Index(mediaType, ";"); semi >= 0 {
+		mediaType = mediaType[:semi]
+	}
+	parts := strings.SplitN(strings.TrimSpace(mediaType), "/", 2)
+	if len(parts) != 2 {
+		return -1
+	}
+	if ar.mimeType == "*" {
+		return 0
+	}
+	if ar.mimeType != parts[0] {
+		return -1
+	}
+	if ar.subType == "*" {
+		return 1
+	}
+	if ar.subType != parts[1] {
+		return -1
+	}
+	return 2
+}
+
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		panic("tgin: you must provide at least one offer")
+	}
+	accepted := parseAccept(c.Request.Header.Get("Accept"))
+	if len(accepted) == 0 {
+		return offered[0]
+	}
+	best := ""
+	bestQuality := 0.0
+	bestSpecificity := -1
+	for _, offer := range offered {
+		quality := 0.0
+		specificity := -1
+		for _, ar := range accepted {
+			s := ar.match(offer)
+			if s > specificity {
+				specificity = s
+				quality = ar.quality
+			}
+		}
+		if specificity < 0 || quality <= 0 {
+			continue
+		}
+		if quality > bestQuality || (quality == bestQuality && specificity > bestSpecificity) {
+			best = offer
+			bestQuality = quality
+			bestSpecificity = specificity
+		}
+	}
+	return best
+}
+
+func (c *Context) Negotiate(code int, config Negotiate) {
+	switch c.NegotiateFormat(config.Offered...) {
+	case MIMEJSON:
+		c.JSON(code, chooseData(config.JSONData, config.Data))
+	case MIMEHTML:
+		c.HTML(code, config.HTMLName, chooseData(config.HTMLData, config.Data))
+	case MIMEXML, MIMEXML2:
+		c.XML(code, chooseData(config.XMLData, config.Data))
+	case MIMEPlain:
+		c.Text(code, fmt.Sprint(config.Data))
+	default:
+		c.Text(406, "406 not acceptable\n")
+		c.Abort()
+	}
+}
+
+func chooseData(custom, wildcard interface{}) interface{} {
+	if custom != nil {
+		return custom
+	}
+	return wildcard
+}
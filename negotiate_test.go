@@ -0,0 +1,68 @@
+package tgin
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func negotiateRequest(accept string) *Context {
+	req := getRequest("/", "")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	return createTestContext(req)
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	ctx := negotiateRequest("")
+	assertEqual(t, MIMEJSON, ctx.NegotiateFormat(MIMEJSON, MIMEXML))
+
+	ctx = negotiateRequest("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	assertEqual(t, MIMEHTML, ctx.NegotiateFormat(MIMEJSON, MIMEHTML))
+	assertEqual(t, MIMEXML, ctx.NegotiateFormat(MIMEJSON, MIMEXML))
+	assertEqual(t, MIMEJSON, ctx.NegotiateFormat(MIMEJSON, MIMEPlain))
+
+	ctx = negotiateRequest("application/xml;q=0.5, application/json")
+	assertEqual(t, MIMEJSON, ctx.NegotiateFormat(MIMEXML, MIMEJSON))
+
+	ctx = negotiateRequest("text/*;q=0.3, text/plain;q=0, */*;q=0.1")
+	assertEqual(t, MIMEHTML, ctx.NegotiateFormat(MIMEPlain, MIMEHTML))
+	assertEqual(t, MIMEJSON, ctx.NegotiateFormat(MIMEPlain, MIMEJSON))
+
+	ctx = negotiateRequest("image/png")
+	assertEqual(t, "", ctx.NegotiateFormat(MIMEJSON, MIMEXML))
+}
+
+type negotiateData struct {
+	Key string
+}
+
+func TestNegotiate(t *testing.T) {
+	offered := []string{MIMEJSON, MIMEXML}
+	ctx := negotiateRequest("application/xml")
+	ctx.Negotiate(200, Negotiate{
+		Offered: offered,
+		Data:    negotiateData{"value"},
+	})
+	resp := ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, "application/xml; charset=utf-8", resp.Header.Get("Content-Type"))
+	assertBody(t, resp, "<negotiateData><Key>value</Key></negotiateData>")
+
+	ctx = negotiateRequest("application/json")
+	ctx.Negotiate(200, Negotiate{
+		Offered:  offered,
+		JSONData: H{"key": "json"},
+		Data:     H{"key": "value"},
+	})
+	resp = ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertBody(t, resp, "{\"key\":\"json\"}\n")
+
+	ctx = negotiateRequest("text/csv")
+	ctx.Negotiate(200, Negotiate{
+		Offered: offered,
+		Data:    H{"key": "value"},
+	})
+	resp = ctx.Writer.(*httptest.ResponseRecorder).Result()
+	assertEqual(t, 406, resp.StatusCode)
+	assertTrue(t, ctx.aborted)
+}
@@ -0,0 +1,154 @@
+package tgin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. It implements error, so
+// handlers can record it with c.Error to control the rendered response.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+	}
+	return fmt.Sprintf("%d %s", p.Status, p.Title)
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	data := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, val := range p.Extensions {
+		data[key] = val
+	}
+	typ := p.Type
+	if typ == "" {
+		typ = "about:blank"
+	}
+	data["type"] = typ
+	if p.Title != "" {
+		data["title"] = p.Title
+	}
+	if p.Status != 0 {
+		data["status"] = p.Status
+	}
+	if p.Detail != "" {
+		data["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		data["instance"] = p.Instance
+	}
+	return json.Marshal(data)
+}
+
+type problemMapping struct {
+	target  error
+	problem Problem
+}
+
+// ProblemRegistry maps errors to problem details. Errors are matched with
+// errors.Is in registration order, then by the registered functions.
+type ProblemRegistry struct {
+	mappings []problemMapping
+	funcs    []func(err error) *Problem
+}
+
+func NewProblemRegistry() *ProblemRegistry {
+	return &ProblemRegistry{}
+}
+
+func (r *ProblemRegistry) Register(target error, problem Problem) *ProblemRegistry {
+	r.mappings = append(r.mappings, problemMapping{target, problem})
+	return r
+}
+
+func (r *ProblemRegistry) RegisterFunc(fn func(err error) *Problem) *ProblemRegistry {
+	r.funcs = append(r.funcs, fn)
+	return r
+}
+
+func (r *ProblemRegistry) lookup(err error) *Problem {
+	if r == nil {
+		return nil
+	}
+	for _, mapping := range r.mappings {
+		if errors.Is(err, mapping.target) {
+			problem := mapping.problem
+			return &problem
+		}
+	}
+	for _, fn := range r.funcs {
+		if problem := fn(err); problem != nil {
+			copied := *problem
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (r *ProblemRegistry) problemFor(err *Error) *Problem {
+	var problem *Problem
+	if errors.As(err.Err, &problem) {
+		copied := *problem
+		problem = &copied
+	} else if problem = r.lookup(err.Err); problem == nil {
+		problem = &Problem{Status: http.StatusInternalServerError}
+		if err.IsType(ErrorTypeBind) {
+			problem.Status = http.StatusBadRequest
+		}
+	}
+	if problem.Detail == "" && err.IsType(ErrorTypePublic|ErrorTypeBind) {
+		problem.Detail = err.Error()
+	}
+	return problem
+}
+
+// Problem renders problem as application/problem+json. A missing status
+// defaults to 500, and missing title and instance are filled from the status
+// and the request path, without modifying problem.
+func (c *Context) Problem(problem *Problem) {
+	p := *problem
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	buf, err := encodeJSON(&p, false, true)
+	if err != nil {
+		c.Text(500, fmt.Sprintf("Server Error!\n%v", err))
+		return
+	}
+	c.render(p.Status, MIMEProblemJSON, buf)
+}
+
+// ProblemMiddleware renders the last error recorded with c.Error as an
+// application/problem+json response, unless the response was already written.
+// Details of private errors are not exposed to clients.
+func ProblemMiddleware(registry *ProblemRegistry) RouteHandler {
+	return func(c *Context) {
+		c.Next()
+		err := c.Errors.Last()
+		if err == nil {
+			return
+		}
+		if ww, ok := unwrapResponseWriter(c.Writer); ok && ww.Written() {
+			return
+		}
+		c.Problem(registry.problemFor(err))
+	}
+}
@@ -0,0 +1,134 @@
+package tgin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var errNotFound = errors.New("record not found")
+
+type validationError struct {
+	field string
+}
+
+func (e *validationError) Error() string {
+	return "invalid field " + e.field
+}
+
+func decodeProblem(t *testing.T, resp *http.Response) map[string]interface{} {
+	assertEqual(t, MIMEProblemJSON, resp.Header.Get("Content-Type"))
+	data := map[string]interface{}{}
+	err := json.NewDecoder(resp.Body).Decode(&data)
+	assertNil(t, err)
+	return data
+}
+
+func newProblemRouter() *RouteGroup {
+	registry := NewProblemRegistry().
+		Register(errNotFound, Problem{Status: 404, Type: "https://example.com/probs/not-found"}).
+		RegisterFunc(func(err error) *Problem {
+			var verr *validationError
+			if errors.As(err, &verr) {
+				return &Problem{Status: 422, Detail: verr.Error(), Extensions: map[string]interface{}{"field": verr.field}}
+			}
+			return nil
+		})
+	r := NewRouteGroup()
+	r.Use(ProblemMiddleware(registry))
+	r.Get("/notfound", func(c *Context) {
+		c.Error(fmt.Errorf("load user: %w", errNotFound))
+	})
+	r.Get("/invalid", func(c *Context) {
+		c.Error(&validationError{"email"})
+	})
+	r.Get("/private", func(c *Context) {
+		c.Error(errors.New("db password wrong"))
+	})
+	r.Get("/problem", func(c *Context) {
+		c.Error(&Problem{Status: 409, Title: "Conflict", Detail: "version mismatch"})
+	})
+	r.Get("/written", func(c *Context) {
+		c.String(200, "OK")
+		c.Error(errNotFound)
+	})
+	return r
+}
+
+func TestProblemMiddlewareRegistry(t *testing.T) {
+	r := newProblemRouter()
+	resp := processRequest(r, "GET", "/notfound")
+	assertEqual(t, 404, resp.StatusCode)
+	data := decodeProblem(t, resp)
+	assertEqual(t, "https://example.com/probs/not-found", data["type"])
+	assertEqual(t, "Not Found", data["title"])
+	assertEqual(t, float64(404), data["status"])
+	assertEqual(t, "/notfound", data["instance"])
+	assertNil(t, data["detail"])
+
+	resp = processRequest(r, "GET", "/invalid")
+	assertEqual(t, 422, resp.StatusCode)
+	data = decodeProblem(t, resp)
+	assertEqual(t, "invalid field email", data["detail"])
+	assertEqual(t, "email", data["field"])
+}
+
+func TestProblemMiddlewareDefaults(t *testing.T) {
+	r := newProblemRouter()
+	resp := processRequest(r, "GET", "/private")
+	assertEqual(t, 500, resp.StatusCode)
+	data := decodeProblem(t, resp)
+	assertEqual(t, "about:blank", data["type"])
+	assertEqual(t, "Internal Server Error", data["title"])
+	assertNil(t, data["detail"], "Private error detail should be hidden")
+
+	resp = processRequest(r, "GET", "/problem")
+	assertEqual(t, 409, resp.StatusCode)
+	data = decodeProblem(t, resp)
+	assertEqual(t, "version mismatch", data["detail"])
+
+	resp = processRequest(r, "GET", "/written")
+	assertEqual(t, 200, resp.StatusCode)
+	assertBody(t, resp, "OK")
+}
+
+func TestContextProblemDefaults(t *testing.T) {
+	problem := &Problem{Detail: "something broke"}
+	r := NewRouteGroup()
+	r.Get("/broken", func(c *Context) {
+		c.Problem(problem)
+	})
+	resp := processRequest(r, "GET", "/broken")
+	assertEqual(t, 500, resp.StatusCode)
+	data := decodeProblem(t, resp)
+	assertEqual(t, "Internal Server Error", data["title"])
+	assertEqual(t, float64(500), data["status"])
+	assertEqual(t, "/broken", data["instance"])
+	assertEqual(t, 0, problem.Status)
+	assertEqual(t, "", problem.Title)
+	assertEqual(t, "", problem.Instance)
+}
+
+func TestProblemRegistryFuncSharedProblem(t *testing.T) {
+	shared := &Problem{Status: 403, Title: "Forbidden"}
+	registry := NewProblemRegistry().RegisterFunc(func(err error) *Problem {
+		return shared
+	})
+	r := NewRouteGroup()
+	r.Use(ProblemMiddleware(registry))
+	r.Get("/a", func(c *Context) {
+		c.Error(errors.New("secret for alice")).SetType(ErrorTypePublic)
+	})
+	r.Get("/b", func(c *Context) {
+		c.Error(errors.New("private failure"))
+	})
+	data := decodeProblem(t, processRequest(r, "GET", "/a"))
+	assertEqual(t, "secret for alice", data["detail"])
+
+	data = decodeProblem(t, processRequest(r, "GET", "/b"))
+	assertEqual(t, float64(403), data["status"])
+	assertNil(t, data["detail"], "Detail of a previous request should not leak")
+	assertEqual(t, "", shared.Detail)
+}
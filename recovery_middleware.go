@@ -2,12 +2,16 @@ package tgin
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
-	"net"
-	"os"
+	"net/http"
 	"runtime"
+	"sort"
 	"strings"
+	"syscall"
 )
 
 var (
@@ -17,44 +21,181 @@ var (
 	slash = []byte("/")
 )
 
+// RecoveryStackKey holds the stack of a recovered panic as []byte, so
+// RecoveryFunc handlers can report it.
+const RecoveryStackKey = "tgin.recovery.stack"
+
+type RecoveryFunc func(c *Context, err interface{})
+
+var defaultRecovery = CustomRecoveryWithWriter(nil, defaultHandleRecovery)
+
 func RecoveryMiddleware(c *Context) {
-	defer handlePanic(c)
-	c.Next()
+	defaultRecovery(c)
 }
 
-func handlePanic(c *Context) {
-	if err := recover(); err != nil {
-		brokenPipe := false
-		if ne, ok := err.(*net.OpError); ok {
-			if se, ok := ne.Err.(*os.SyscallError); ok {
-				if strings.Contains(strings.ToLower(se.Error()), "broken pipe") || strings.Contains(strings.ToLower(se.Error()), "connection reset by peer") {
-					brokenPipe = true
-				}
-			}
+func CustomRecovery(handle RecoveryFunc) RouteHandler {
+	return CustomRecoveryWithWriter(nil, handle)
+}
+
+func RecoveryWithWriter(out io.Writer, recovery ...RecoveryFunc) RouteHandler {
+	if len(recovery) > 0 {
+		return CustomRecoveryWithWriter(out, recovery[0])
+	}
+	return CustomRecoveryWithWriter(out, defaultHandleRecovery)
+}
+
+var defaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+type RecoveryConfig struct {
+	// Output receives the panic log. Nil logs through the standard log package,
+	// except in TestMode.
+	Output io.Writer
+
+	// Handle writes the response for a recovered panic.
+	Handle RecoveryFunc
+
+	// RedactHeaders lists request headers masked in the panic log. Nil masks
+	// Authorization, Proxy-Authorization and Cookie, an empty slice masks none.
+	RedactHeaders []string
+}
+
+func CustomRecoveryWithWriter(out io.Writer, handle RecoveryFunc) RouteHandler {
+	return RecoveryWithConfig(RecoveryConfig{
+		Output: out,
+		Handle: handle,
+	})
+}
+
+// RecoveryWithConfig recovers from panics, logs them together with the
+// request headers and the stack, and lets conf.Handle write the response.
+// Panics caused by a client closing the connection are only logged.
+func RecoveryWithConfig(conf RecoveryConfig) RouteHandler {
+	printf := log.Printf
+	if conf.Output != nil {
+		printf = log.New(conf.Output, "", log.LstdFlags).Printf
+	}
+	return recoveryHandler(conf, func(c *Context, err interface{}, request string, stack []byte) {
+		if conf.Output == nil && Mode() == TestMode {
+			return
+		}
+		prefix := "[Recovery] "
+		if id := c.RequestID(); id != "" {
+			prefix += "[" + id + "] "
 		}
-		stack := dumpStack(3)
-		log.Printf("[Recovery] panic recovered: %s\n%s\n", err, stack)
-		if brokenPipe {
-			c.Abort()
-		} else {
-			c.AbortWithStatus(500)
+		if stack == nil {
+			printf("%sconnection closed by client: %s\n%s", prefix, err, request)
+			return
+		}
+		printf("%spanic recovered: %s\n%s\n%s\n", prefix, err, request, stack)
+	})
+}
+
+// panicLogger logs a recovered panic. Stack is nil when the client closed
+// the connection.
+type panicLogger func(c *Context, err interface{}, request string, stack []byte)
+
+func recoveryHandler(conf RecoveryConfig, logPanic panicLogger) RouteHandler {
+	if conf.Handle == nil {
+		conf.Handle = defaultHandleRecovery
+	}
+	if conf.RedactHeaders == nil {
+		conf.RedactHeaders = defaultRedactHeaders
+	}
+	return func(c *Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				handlePanic(c, err, logPanic, conf)
+			}
+		}()
+		c.Next()
+	}
+}
+
+func isBrokenPipe(err interface{}) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	return errors.Is(e, syscall.EPIPE) || errors.Is(e, syscall.ECONNRESET)
+}
+
+func dumpRequest(r *http.Request, redactHeaders []string) string {
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "%s %s %s\n", r.Method, r.URL.RequestURI(), r.Proto)
+	keys := make([]string, 0, len(r.Header))
+	for key := range r.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := strings.Join(r.Header[key], ", ")
+		for _, redact := range redactHeaders {
+			if strings.EqualFold(key, redact) {
+				value = "*"
+				break
+			}
 		}
+		fmt.Fprintf(buf, "%s: %s\n", key, value)
 	}
+	return buf.String()
+}
+
+func handlePanic(c *Context, err interface{}, logPanic panicLogger, conf RecoveryConfig) {
+	request := dumpRequest(c.Request, conf.RedactHeaders)
+	if isBrokenPipe(err) {
+		logPanic(c, err, request, nil)
+		c.Abort()
+		return
+	}
+	stack := dumpStack(4)
+	logPanic(c, err, request, stack)
+	c.Set(RecoveryStackKey, stack)
+	conf.Handle(c, err)
+	c.Abort()
+}
+
+// defaultHandleRecovery answers 500, including the panic and its stack in
+// debug mode.
+func defaultHandleRecovery(c *Context, err interface{}) {
+	if stack, have := c.Get(RecoveryStackKey); have && IsDebugging() {
+		c.Text(500, fmt.Sprintf("panic: %v\n\n%s", err, stack))
+		return
+	}
+	c.AbortWithStatus(500)
 }
 
 func dumpStack(skip int) []byte {
 	buf := bytes.NewBuffer(nil)
+	var lines [][]byte
+	var lastFile string
 	for i := skip; ; i++ {
 		pc, file, line, ok := runtime.Caller(i)
 		if !ok {
 			break
 		}
 		fmt.Fprintf(buf, "%s:%d (0x%x)\n", file, line, pc)
-		fmt.Fprintf(buf, "\tfunc: %s\n", function(pc))
+		if file != lastFile {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				lines = nil
+			} else {
+				lines = bytes.Split(data, []byte{'\n'})
+			}
+			lastFile = file
+		}
+		fmt.Fprintf(buf, "\t%s: %s\n", function(pc), source(lines, line))
 	}
 	return buf.Bytes()
 }
 
+func source(lines [][]byte, n int) []byte {
+	n--
+	if n < 0 || n >= len(lines) {
+		return dunno
+	}
+	return bytes.TrimSpace(lines[n])
+}
+
 func function(pc uintptr) []byte {
 	fn := runtime.FuncForPC(pc)
 	if fn == nil {
@@ -0,0 +1,95 @@
+package tgin
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+const (
+	RequestIDKey    = "tgin.request_id"
+	RequestIDHeader = "X-Request-ID"
+)
+
+const maxRequestIDLength = 128
+
+type RequestIDConfig struct {
+	// Header carries the request ID in both directions. Empty means
+	// X-Request-ID.
+	Header string
+
+	// Generator creates IDs for requests without a valid one. Nil means
+	// NewRequestID.
+	Generator func() string
+}
+
+func RequestID() RouteHandler {
+	return RequestIDWithConfig(RequestIDConfig{})
+}
+
+// RequestIDWithConfig reuses the incoming request ID or generates one, stores
+// it under RequestIDKey and echoes it in the response header. Incoming IDs
+// which are too long or contain non-printable characters are replaced, so
+// they cannot forge log lines.
+func RequestIDWithConfig(conf RequestIDConfig) RouteHandler {
+	if conf.Header == "" {
+		conf.Header = RequestIDHeader
+	}
+	if conf.Generator == nil {
+		conf.Generator = NewRequestID
+	}
+	return func(c *Context) {
+		id := c.GetHeader(conf.Header)
+		if !validRequestID(id) {
+			id = conf.Generator()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(conf.Header, id)
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID returns the ID stored by the RequestID middleware, or an empty
+// string without it.
+func (c *Context) RequestID() string {
+	if id, have := c.Get(RequestIDKey); have {
+		if s, ok := id.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewRequestID returns a ULID-style ID: 26 Crockford base32 characters
+// encoding a 48 bit millisecond timestamp and 80 random bits, so IDs sort
+// by creation time.
+func NewRequestID() string {
+	var data [16]byte
+	binary.BigEndian.PutUint64(data[:8], uint64(time.Now().UnixNano()/int64(time.Millisecond))<<16)
+	if _, err := rand.Read(data[6:]); err != nil {
+		panic(err)
+	}
+	hi := binary.BigEndian.Uint64(data[:8])
+	lo := binary.BigEndian.Uint64(data[8:])
+	var id [26]byte
+	for i := 25; i >= 0; i-- {
+		id[i] = crockfordBase32[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(id[:])
+}
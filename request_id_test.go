@@ -0,0 +1,80 @@
+package tgin
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDGenerated(t *testing.T) {
+	var id string
+	r := NewRouteGroup()
+	r.Use(RequestID())
+	r.Get("/", func(c *Context) {
+		id = c.RequestID()
+		c.String(200, "ok")
+	})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, 26, len(id))
+	assertEqual(t, id, resp.Header.Get(RequestIDHeader))
+
+	first := id
+	processRequest(r, "GET", "/")
+	assertNotEqual(t, first, id)
+}
+
+func TestRequestIDPropagated(t *testing.T) {
+	var id string
+	r := NewRouteGroup()
+	r.Use(RequestIDWithConfig(RequestIDConfig{Header: "X-Trace"}))
+	r.Get("/", func(c *Context) {
+		id = c.RequestID()
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Trace", "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "abc-123", id)
+	assertEqual(t, "abc-123", w.Header().Get("X-Trace"))
+
+	req.Header.Set("X-Trace", "forged\nline")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, 26, len(id))
+	assertEqual(t, id, w.Header().Get("X-Trace"))
+}
+
+func TestRequestIDGenerator(t *testing.T) {
+	r := NewRouteGroup()
+	r.Use(RequestIDWithConfig(RequestIDConfig{Generator: func() string { return "fixed" }}))
+	r.Get("/", func(c *Context) {})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, "fixed", resp.Header.Get(RequestIDHeader))
+}
+
+func TestNewRequestID(t *testing.T) {
+	first := NewRequestID()
+	second := NewRequestID()
+	assertEqual(t, 26, len(first))
+	assertNotEqual(t, first, second)
+	assertTrue(t, first[0] <= '7', first)
+	for _, ch := range first {
+		assertTrue(t, strings.ContainsRune(crockfordBase32, ch), first)
+	}
+}
+
+func TestRequestIDInLogs(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	r := NewRouteGroup()
+	r.Use(RequestID(), LoggerWithWriter(buf), RecoveryWithWriter(buf))
+	r.Get("/panic", func(c *Context) {
+		panic("tagged")
+	})
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	output := buf.String()
+	assertTrue(t, strings.Contains(output, "[Recovery] [req-42] panic recovered: tagged"), output)
+	assertTrue(t, strings.Contains(output, "GET /panic | req-42\n"), output)
+}
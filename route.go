@@ -17,6 +17,7 @@ type RouteGroup struct {
 	handlers    map[string]handlerFunctions
 	mux         *http.ServeMux
 	middlewares *MiddlewareTree
+	engine      *Engine
 }
 
 func NewRouteGroup() *RouteGroup {
@@ -29,20 +30,15 @@ func NewRouteGroup() *RouteGroup {
 }
 
 func (rg *RouteGroup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ww := &ResponseWriterWrapper{
-		ResponseWriter: w,
-		code:           200,
-	}
-	if hj, ok := w.(http.Hijacker); ok {
-		ww.Hijacker = hj
-	}
-	ctx := newContext(ww, r)
+	rw, ww := wrapResponseWriter(w)
+	ctx := newContext(rw, r)
 	ww.ctx = ctx
 	ctx.mux = rg.mux
+	ctx.engine = rg.engine
 	ctx.middlewares = rg.middlewares.BuildMiddlewares(r.URL.Path)
 	ctx.Next()
 	if !ctx.aborted && !ctx.served {
-		rg.mux.ServeHTTP(ww, r)
+		rg.mux.ServeHTTP(rw, r)
 	}
 }
 
@@ -51,7 +47,7 @@ func (rg *RouteGroup) getPath(path string) string {
 }
 
 func (rg *RouteGroup) getContext(w http.ResponseWriter, r *http.Request) *Context {
-	if rww, ok := w.(*ResponseWriterWrapper); ok {
+	if rww, ok := unwrapResponseWriter(w); ok {
 		if rww.ctx != nil {
 			return rww.ctx
 		}
@@ -61,6 +57,7 @@ func (rg *RouteGroup) getContext(w http.ResponseWriter, r *http.Request) *Contex
 
 func (rg *RouteGroup) handle(method, path string, handler RouteHandler) {
 	fullPath := rg.getPath(path)
+	debugPrint("%-7s %s", method, fullPath)
 	hfs, have := rg.handlers[fullPath]
 	if have {
 		hfs[method] = handler
@@ -70,6 +67,7 @@ func (rg *RouteGroup) handle(method, path string, handler RouteHandler) {
 		rg.handlers[fullPath] = nhfs
 		rg.mux.HandleFunc(fullPath, func(w http.ResponseWriter, r *http.Request) {
 			ctx := rg.getContext(w, r)
+			ctx.fullPath = fullPath
 			lhfs, have := rg.handlers[fullPath]
 			if !have {
 				ctx.Text(404, "404 page not found\n")
@@ -91,12 +89,16 @@ func (rg *RouteGroup) Group(prefix string) *RouteGroup {
 		mux:         rg.mux,
 		middlewares: rg.middlewares,
 		handlers:    map[string]handlerFunctions{},
+		engine:      rg.engine,
 	}
 }
 
 func (rg *RouteGroup) Any(path string, handler RouteHandler) {
-	rg.mux.HandleFunc(rg.getPath(path), func(w http.ResponseWriter, r *http.Request) {
+	fullPath := rg.getPath(path)
+	debugPrint("%-7s %s", "ANY", fullPath)
+	rg.mux.HandleFunc(fullPath, func(w http.ResponseWriter, r *http.Request) {
 		ctx := rg.getContext(w, r)
+		ctx.fullPath = fullPath
 		handler(ctx)
 	})
 }
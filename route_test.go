@@ -2,9 +2,15 @@ package tgin
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
+	"syscall"
 	"testing"
 )
 
@@ -284,6 +290,101 @@ func TestRecoveryMiddlewareWithPanicInMiddleware(t *testing.T) {
 	assertEqual(t, 0, handlerRun)
 }
 
+func TestCustomRecovery(t *testing.T) {
+	r := NewRouteGroup()
+	var stack []byte
+	r.Use(CustomRecovery(func(c *Context, err interface{}) {
+		val, _ := c.Get(RecoveryStackKey)
+		stack, _ = val.([]byte)
+		c.JSON(500, H{"error": fmt.Sprint(err)})
+	}))
+	r.Get("/", func(c *Context) {
+		panic("custom panic")
+	})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, 500, resp.StatusCode)
+	assertBody(t, resp, "{\"error\":\"custom panic\"}\n")
+	assertTrue(t, bytes.Contains(stack, []byte("TestCustomRecovery")), "Handler should get the stack outside debug mode")
+}
+
+func TestRecoveryWithWriter(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(ReleaseMode)
+	buf := bytes.NewBuffer(nil)
+	r := NewRouteGroup()
+	r.Use(RecoveryWithWriter(buf))
+	r.Get("/", func(c *Context) {
+		panic("writer panic")
+	})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, 500, resp.StatusCode)
+	assertBody(t, resp, "")
+	assertTrue(t, strings.Contains(buf.String(), "[Recovery] panic recovered: writer panic"), buf.String())
+	assertTrue(t, strings.Contains(buf.String(), "TestRecoveryWithWriter"), "Log should contain stack")
+}
+
+func TestRecoveryStackInDebugMode(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+	r := NewRouteGroup()
+	r.Use(RecoveryWithWriter(ioutil.Discard))
+	r.Get("/", func(c *Context) {
+		panic("debug panic")
+	})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, 500, resp.StatusCode)
+	body := ReadBodyString(resp)
+	assertTrue(t, strings.HasPrefix(body, "panic: debug panic\n\n"), body)
+	assertTrue(t, strings.Contains(body, "TestRecoveryStackInDebugMode"), body)
+}
+
+func TestRecoveryBrokenPipe(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	r := NewRouteGroup()
+	r.Use(RecoveryWithWriter(buf))
+	r.Get("/", func(c *Context) {
+		opErr := &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+		panic(fmt.Errorf("copy body: %w", opErr))
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	w := &countHeaderRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r.ServeHTTP(w, req)
+	assertEqual(t, 0, w.headerWrites, "Broken pipe should not write response")
+	assertTrue(t, strings.Contains(buf.String(), "connection closed by client"), buf.String())
+	assertFalse(t, isBrokenPipe(errors.New("broken pipe")), "Plain error should not be broken pipe")
+	assertTrue(t, isBrokenPipe(os.NewSyscallError("read", syscall.ECONNRESET)))
+}
+
+func TestRecoveryRedactHeaders(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	r := NewRouteGroup()
+	r.Use(RecoveryWithWriter(buf))
+	r.Get("/", func(c *Context) {
+		panic("redact panic")
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-Trace", "visible")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assertFalse(t, strings.Contains(buf.String(), "secret-token"), buf.String())
+	assertTrue(t, strings.Contains(buf.String(), "Authorization: *"), buf.String())
+	assertTrue(t, strings.Contains(buf.String(), "X-Trace: visible"), buf.String())
+
+	buf.Reset()
+	r = NewRouteGroup()
+	r.Use(RecoveryWithConfig(RecoveryConfig{Output: buf, RedactHeaders: []string{}}))
+	r.Get("/", func(c *Context) {
+		panic("redact panic")
+	})
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assertTrue(t, strings.Contains(buf.String(), "Authorization: Bearer secret-token"), buf.String())
+}
+
+func TestDumpStackSourceLine(t *testing.T) {
+	stack := string(dumpStack(0))
+	assertTrue(t, strings.Contains(stack, "TestDumpStackSourceLine: stack := string(dumpStack(0))"), stack)
+}
+
 func TestStaticFileMiddleware(t *testing.T) {
 	r := NewRouteGroup()
 	r.Use(StaticFileMiddleware("/", "/tmp", true))
@@ -296,3 +397,17 @@ func TestStaticFileMiddleware(t *testing.T) {
 	assertEqual(t, 200, resp.StatusCode)
 	assertBody(t, resp, "Hello world")
 }
+
+func TestOnWriteHeader(t *testing.T) {
+	r := NewRouteGroup()
+	r.Use(func(c *Context) {
+		c.OnWriteHeader(func() {
+			c.Header("X-Hook", "called")
+		})
+	})
+	r.Get("/", func(c *Context) {
+		c.String(200, "OK")
+	})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, "called", resp.Header.Get("X-Hook"))
+}
@@ -0,0 +1,104 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/blacktear23/tgin"
+)
+
+var ErrSessionExpired = errors.New("session expired")
+
+type cookiePayload struct {
+	Values  map[string]interface{}
+	Expires int64
+}
+
+// CookieStore keeps the whole session in a signed or encrypted cookie. Values
+// are gob encoded, so custom types must be registered with gob.Register.
+type CookieStore struct {
+	Options Options
+	codec   *tgin.CookieCodec
+	encrypt bool
+}
+
+func NewCookieStore(secrets ...[]byte) *CookieStore {
+	return &CookieStore{
+		Options: DefaultOptions(),
+		codec:   tgin.NewCookieCodec(secrets...),
+	}
+}
+
+func NewEncryptedCookieStore(secrets ...[]byte) *CookieStore {
+	store := NewCookieStore(secrets...)
+	store.encrypt = true
+	return store
+}
+
+func (s *CookieStore) Load(c *tgin.Context, name string) (*Session, error) {
+	session := NewSession(s, name)
+	session.Options = s.Options
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	var value string
+	if s.encrypt {
+		value, err = s.codec.Decrypt(name, cookie.Value)
+	} else {
+		value, err = s.codec.Verify(name, cookie.Value)
+	}
+	if err != nil {
+		return session, err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return session, err
+	}
+	payload := cookiePayload{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&payload); err != nil {
+		return session, err
+	}
+	if payload.Expires > 0 && time.Now().Unix() > payload.Expires {
+		return session, ErrSessionExpired
+	}
+	if payload.Values != nil {
+		session.Values = payload.Values
+	}
+	session.IsNew = false
+	return session, nil
+}
+
+func (s *CookieStore) Save(c *tgin.Context, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		http.SetCookie(c.Writer, session.cookie(""))
+		return nil
+	}
+	payload := cookiePayload{Values: session.Values}
+	if session.Options.MaxAge > 0 {
+		payload.Expires = time.Now().Add(time.Duration(session.Options.MaxAge) * time.Second).Unix()
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := gob.NewEncoder(buf).Encode(&payload); err != nil {
+		return err
+	}
+	value := base64.RawURLEncoding.EncodeToString(buf.Bytes())
+	var (
+		encoded string
+		err     error
+	)
+	if s.encrypt {
+		encoded, err = s.codec.Encrypt(session.name, value)
+	} else {
+		encoded, err = s.codec.Sign(session.name, value)
+	}
+	if err != nil {
+		return err
+	}
+	http.SetCookie(c.Writer, session.cookie(encoded))
+	return nil
+}
@@ -0,0 +1,85 @@
+package sessions
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	values  map[string]interface{}
+	expires time.Time
+}
+
+type MemoryBackend struct {
+	sync.Mutex
+	entries       map[string]memoryEntry
+	sweepInterval time.Duration
+	lastSweep     time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		entries:       make(map[string]memoryEntry),
+		sweepInterval: time.Minute,
+		lastSweep:     time.Now(),
+	}
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(values))
+	for key, val := range values {
+		ret[key] = val
+	}
+	return ret
+}
+
+func (b *MemoryBackend) Load(id string) (map[string]interface{}, bool, error) {
+	b.Lock()
+	defer b.Unlock()
+	entry, have := b.entries[id]
+	if !have {
+		return nil, false, nil
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(b.entries, id)
+		return nil, false, nil
+	}
+	return copyValues(entry.values), true, nil
+}
+
+func (b *MemoryBackend) Save(id string, values map[string]interface{}, ttl time.Duration) error {
+	b.Lock()
+	defer b.Unlock()
+	now := time.Now()
+	entry := memoryEntry{values: copyValues(values)}
+	if ttl > 0 {
+		entry.expires = now.Add(ttl)
+	}
+	b.entries[id] = entry
+	if now.Sub(b.lastSweep) >= b.sweepInterval {
+		b.sweep(now)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Delete(id string) error {
+	b.Lock()
+	delete(b.entries, id)
+	b.Unlock()
+	return nil
+}
+
+func (b *MemoryBackend) Len() int {
+	b.Lock()
+	defer b.Unlock()
+	return len(b.entries)
+}
+
+func (b *MemoryBackend) sweep(now time.Time) {
+	for id, entry := range b.entries {
+		if !entry.expires.IsZero() && now.After(entry.expires) {
+			delete(b.entries, id)
+		}
+	}
+	b.lastSweep = now
+}
@@ -0,0 +1,66 @@
+package sessions
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/blacktear23/tgin"
+)
+
+// Backend persists session values by ID for server side stores. Implement it
+// to keep sessions in an external database such as Redis.
+type Backend interface {
+	Load(id string) (map[string]interface{}, bool, error)
+	Save(id string, values map[string]interface{}, ttl time.Duration) error
+	Delete(id string) error
+}
+
+// ServerStore keeps session values in a Backend and only the session ID in
+// the cookie.
+type ServerStore struct {
+	Options Options
+	backend Backend
+}
+
+func NewServerStore(backend Backend) *ServerStore {
+	return &ServerStore{
+		Options: DefaultOptions(),
+		backend: backend,
+	}
+}
+
+func NewMemoryStore() *ServerStore {
+	return NewServerStore(NewMemoryBackend())
+}
+
+func (s *ServerStore) Load(c *tgin.Context, name string) (*Session, error) {
+	session := NewSession(s, name)
+	session.Options = s.Options
+	cookie, err := c.Request.Cookie(name)
+	if err != nil || cookie.Value == "" {
+		session.ID = GenerateID()
+		return session, nil
+	}
+	values, have, err := s.backend.Load(cookie.Value)
+	if err != nil || !have {
+		session.ID = GenerateID()
+		return session, err
+	}
+	session.ID = cookie.Value
+	session.Values = values
+	session.IsNew = false
+	return session, nil
+}
+
+func (s *ServerStore) Save(c *tgin.Context, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		http.SetCookie(c.Writer, session.cookie(""))
+		return s.backend.Delete(session.ID)
+	}
+	ttl := time.Duration(session.Options.MaxAge) * time.Second
+	if err := s.backend.Save(session.ID, session.Values, ttl); err != nil {
+		return err
+	}
+	http.SetCookie(c.Writer, session.cookie(session.ID))
+	return nil
+}
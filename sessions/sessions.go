@@ -0,0 +1,177 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/blacktear23/tgin"
+)
+
+const DefaultKey = "github.com/blacktear23/tgin/sessions"
+
+type Options struct {
+	Path     string
+	Domain   string
+	MaxAge   int
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Path:     "/",
+		MaxAge:   86400 * 30,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Store loads and saves sessions. Load must always return a session: a new
+// empty one with the store's options and a fresh ID when the request carries
+// none or it is invalid, also together with an error.
+type Store interface {
+	Load(c *tgin.Context, name string) (*Session, error)
+	Save(c *tgin.Context, session *Session) error
+}
+
+type Session struct {
+	ID       string
+	Values   map[string]interface{}
+	Options  Options
+	IsNew    bool
+	name     string
+	store    Store
+	ctx      *tgin.Context
+	modified bool
+}
+
+func NewSession(store Store, name string) *Session {
+	return &Session{
+		Values: make(map[string]interface{}),
+		IsNew:  true,
+		name:   name,
+		store:  store,
+	}
+}
+
+func (s *Session) Name() string {
+	return s.name
+}
+
+func (s *Session) Get(key string) interface{} {
+	return s.Values[key]
+}
+
+func (s *Session) Set(key string, val interface{}) {
+	s.Values[key] = val
+	s.modified = true
+}
+
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.modified = true
+}
+
+func (s *Session) Clear() {
+	for key := range s.Values {
+		delete(s.Values, key)
+	}
+	s.modified = true
+}
+
+// Destroy clears the session and expires its cookie when saved.
+func (s *Session) Destroy() {
+	s.Clear()
+	s.Options.MaxAge = -1
+}
+
+func (s *Session) Modified() bool {
+	return s.modified
+}
+
+func (s *Session) Save() error {
+	err := s.store.Save(s.ctx, s)
+	if err == nil {
+		s.modified = false
+	}
+	return err
+}
+
+func (s *Session) cookie(value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.name,
+		Value:    value,
+		Path:     s.Options.Path,
+		Domain:   s.Options.Domain,
+		MaxAge:   s.Options.MaxAge,
+		Secure:   s.Options.Secure,
+		HttpOnly: s.Options.HttpOnly,
+		SameSite: s.Options.SameSite,
+	}
+}
+
+func GenerateID() string {
+	buf := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// logError logs session errors through the standard log package, except in
+// TestMode.
+func logError(format string, values ...interface{}) {
+	if tgin.Mode() != tgin.TestMode {
+		log.Printf("[Sessions] "+format, values...)
+	}
+}
+
+// Sessions loads the named session before the handlers run and saves it if it
+// was modified, either right before the response headers are written or
+// after the handlers return.
+func Sessions(name string, store Store) tgin.RouteHandler {
+	return func(c *tgin.Context) {
+		session, err := store.Load(c, name)
+		if session == nil {
+			panic("sessions: store returned no session for " + name)
+		}
+		if err != nil && err != ErrSessionExpired {
+			logError("load session %s failed: %v", name, err)
+		}
+		session.ctx = c
+		c.Set(DefaultKey, session)
+		c.Set(DefaultKey+"/"+name, session)
+		save := func() {
+			if !session.modified {
+				return
+			}
+			if err := session.Save(); err != nil {
+				logError("save session %s failed: %v", name, err)
+			}
+		}
+		c.OnWriteHeader(save)
+		c.Next()
+		save()
+	}
+}
+
+func Default(c *tgin.Context) *Session {
+	return getSession(c, DefaultKey)
+}
+
+func Get(c *tgin.Context, name string) *Session {
+	return getSession(c, DefaultKey+"/"+name)
+}
+
+func getSession(c *tgin.Context, key string) *Session {
+	val, have := c.Get(key)
+	if !have {
+		return nil
+	}
+	session, _ := val.(*Session)
+	return session
+}
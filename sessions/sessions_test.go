@@ -0,0 +1,238 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blacktear23/tgin"
+)
+
+func newEngine(store Store) *tgin.Engine {
+	e := tgin.New()
+	e.Use(Sessions("session", store))
+	e.Get("/set", func(c *tgin.Context) {
+		session := Default(c)
+		session.Set("user", "alice")
+		session.Set("count", 1)
+		c.String(200, "OK")
+	})
+	e.Get("/get", func(c *tgin.Context) {
+		session := Default(c)
+		user, _ := session.Get("user").(string)
+		count, _ := session.Get("count").(int)
+		c.String(200, "%s:%d", user, count)
+	})
+	e.Get("/destroy", func(c *tgin.Context) {
+		Default(c).Destroy()
+		c.String(200, "OK")
+	})
+	return e
+}
+
+func doRequest(e *tgin.Engine, path string, cookies []*http.Cookie) *http.Response {
+	req := httptest.NewRequest("GET", path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w.Result()
+}
+
+func readBody(resp *http.Response) string {
+	buf := make([]byte, 128)
+	n, _ := resp.Body.Read(buf)
+	return string(buf[:n])
+}
+
+func testStore(t *testing.T, store Store) {
+	e := newEngine(store)
+	resp := doRequest(e, "/get", nil)
+	if body := readBody(resp); body != ":0" {
+		t.Fatalf("Expect empty session but got %s", body)
+	}
+	if len(resp.Cookies()) != 0 {
+		t.Fatalf("Unmodified session should not set cookie")
+	}
+
+	resp = doRequest(e, "/set", nil)
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "session" || !cookies[0].HttpOnly {
+		t.Fatalf("Session cookie not correct: %v", cookies)
+	}
+	resp = doRequest(e, "/get", cookies)
+	if body := readBody(resp); body != "alice:1" {
+		t.Fatalf("Expect alice:1 but got %s", body)
+	}
+
+	resp = doRequest(e, "/destroy", cookies)
+	destroyed := resp.Cookies()
+	if len(destroyed) != 1 || destroyed[0].MaxAge != -1 {
+		t.Fatalf("Destroyed cookie not correct: %v", destroyed)
+	}
+}
+
+func TestCookieStore(t *testing.T) {
+	testStore(t, NewCookieStore([]byte("secret")))
+}
+
+func TestEncryptedCookieStore(t *testing.T) {
+	testStore(t, NewEncryptedCookieStore([]byte("secret")))
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	testStore(t, store)
+	if n := store.backend.(*MemoryBackend).Len(); n != 0 {
+		t.Fatalf("Destroyed session should be deleted but %d left", n)
+	}
+}
+
+func TestCookieStoreTampered(t *testing.T) {
+	e := newEngine(NewCookieStore([]byte("secret")))
+	resp := doRequest(e, "/set", nil)
+	cookies := resp.Cookies()
+	cookies[0].Value = "x" + cookies[0].Value
+	resp = doRequest(e, "/get", cookies)
+	if body := readBody(resp); body != ":0" {
+		t.Fatalf("Tampered session should be ignored but got %s", body)
+	}
+}
+
+func assertSessionCookie(t *testing.T, cookies []*http.Cookie) {
+	if len(cookies) != 1 {
+		t.Fatalf("Expect one session cookie but got %v", cookies)
+	}
+	cookie := cookies[0]
+	if cookie.Value == "" || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" || cookie.MaxAge <= 0 {
+		t.Fatalf("Session cookie should keep store options: %v", cookie)
+	}
+}
+
+func TestCookieStoreTamperedThenSet(t *testing.T) {
+	e := newEngine(NewCookieStore([]byte("secret")))
+	cookies := doRequest(e, "/set", nil).Cookies()
+	cookies[0].Value = "x" + cookies[0].Value
+	resp := doRequest(e, "/set", cookies)
+	assertSessionCookie(t, resp.Cookies())
+	resp = doRequest(e, "/get", resp.Cookies())
+	if body := readBody(resp); body != "alice:1" {
+		t.Fatalf("Expect alice:1 but got %s", body)
+	}
+}
+
+type failingBackend struct {
+	*MemoryBackend
+}
+
+func (b failingBackend) Load(id string) (map[string]interface{}, bool, error) {
+	return nil, false, errors.New("backend down")
+}
+
+func TestServerStoreLoadError(t *testing.T) {
+	backend := failingBackend{NewMemoryBackend()}
+	e := newEngine(NewServerStore(backend))
+	resp := doRequest(e, "/set", []*http.Cookie{{Name: "session", Value: "stale"}})
+	assertSessionCookie(t, resp.Cookies())
+	if backend.Len() != 1 {
+		t.Fatalf("Session should be saved under a new ID")
+	}
+	if _, have, _ := backend.MemoryBackend.Load(""); have {
+		t.Fatalf("Session should not be saved under an empty ID")
+	}
+}
+
+type nilStore struct {
+	*CookieStore
+}
+
+func (s nilStore) Load(c *tgin.Context, name string) (*Session, error) {
+	return nil, errors.New("broken store")
+}
+
+func TestNilSessionPanics(t *testing.T) {
+	e := tgin.New()
+	e.Use(Sessions("session", nilStore{NewCookieStore([]byte("secret"))}))
+	e.Get("/", func(c *tgin.Context) {
+		c.String(200, "OK")
+	})
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Store returning no session should panic")
+		}
+	}()
+	doRequest(e, "/", nil)
+}
+
+func captureLog(fn func()) string {
+	buf := bytes.NewBuffer(nil)
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+	fn()
+	return buf.String()
+}
+
+func TestExpiredSessionNotLogged(t *testing.T) {
+	defer tgin.SetMode(tgin.Mode())
+	tgin.SetMode(tgin.ReleaseMode)
+	store := NewCookieStore([]byte("secret"))
+	e := newEngine(store)
+	buf := bytes.NewBuffer(nil)
+	gob.NewEncoder(buf).Encode(&cookiePayload{
+		Values:  map[string]interface{}{"user": "alice"},
+		Expires: time.Now().Add(-time.Minute).Unix(),
+	})
+	value, _ := store.codec.Sign("session", base64.RawURLEncoding.EncodeToString(buf.Bytes()))
+	var body string
+	output := captureLog(func() {
+		body = readBody(doRequest(e, "/get", []*http.Cookie{{Name: "session", Value: value}}))
+	})
+	if body != ":0" || output != "" {
+		t.Fatalf("Expired session should load empty without logging: %q %q", body, output)
+	}
+
+	output = captureLog(func() {
+		doRequest(e, "/get", []*http.Cookie{{Name: "session", Value: "x" + value}})
+	})
+	if !strings.Contains(output, "[Sessions] load session session failed") {
+		t.Fatalf("Invalid session should be logged: %q", output)
+	}
+}
+
+func TestMemoryBackendExpire(t *testing.T) {
+	backend := NewMemoryBackend()
+	backend.sweepInterval = 0
+	backend.Save("a", map[string]interface{}{"k": "v"}, time.Millisecond)
+	backend.Save("b", map[string]interface{}{"k": "v"}, 0)
+	time.Sleep(5 * time.Millisecond)
+	if _, have, _ := backend.Load("a"); have {
+		t.Fatalf("Expired session should not be loaded")
+	}
+	backend.Save("c", map[string]interface{}{"k": "v"}, time.Millisecond)
+	backend.Lock()
+	_, have := backend.entries["c"]
+	backend.Unlock()
+	if !have || backend.Len() != 2 {
+		t.Fatalf("Sweep result not correct, %d entries left", backend.Len())
+	}
+}
+
+func TestDefaultWithoutMiddleware(t *testing.T) {
+	e := tgin.New()
+	e.Get("/", func(c *tgin.Context) {
+		if Default(c) != nil {
+			t.Fatalf("Default should return nil without middleware")
+		}
+		c.String(200, "OK")
+	})
+	doRequest(e, "/", nil)
+}
@@ -0,0 +1,98 @@
+//go:build go1.21
+// +build go1.21
+
+package tgin
+
+import (
+	"log/slog"
+	"time"
+)
+
+const slogLoggerKey = "tgin.slog.logger"
+
+type SlogConfig struct {
+	// Logger receives the request logs. Nil means slog.Default().
+	Logger *slog.Logger
+
+	// SkipPaths are request paths which get a logger but no request record.
+	SkipPaths []string
+}
+
+func SlogMiddleware(logger *slog.Logger, skipPaths ...string) RouteHandler {
+	return SlogWithConfig(SlogConfig{Logger: logger, SkipPaths: skipPaths})
+}
+
+// SlogWithConfig installs a request-scoped logger, available through
+// c.Logger(), and logs one record per request when the handlers return.
+// Server errors are logged at error level and client errors at warn level.
+func SlogWithConfig(conf SlogConfig) RouteHandler {
+	skip := skipPathSet(conf.SkipPaths)
+	return func(c *Context) {
+		begin := time.Now()
+		logger := conf.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		r := c.Request
+		path := r.URL.Path
+		attrs := []interface{}{slog.String("method", r.Method), slog.String("path", path)}
+		if id := c.RequestID(); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		c.SetLogger(logger.With(attrs...))
+		c.Next()
+		if skip[path] {
+			return
+		}
+		ww, ok := unwrapResponseWriter(c.Writer)
+		if !ok {
+			return
+		}
+		status := ww.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		attrs = []interface{}{
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(begin)),
+			slog.Int("bytes", ww.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if errs := c.Errors.ByType(ErrorTypePrivate); len(errs) > 0 {
+			attrs = append(attrs, slog.Any("errors", errs.Errors()))
+		}
+		c.Logger().Log(r.Context(), level, "request", attrs...)
+	}
+}
+
+// Logger returns the request-scoped logger installed by SlogMiddleware, or
+// slog.Default() without it.
+func (c *Context) Logger() *slog.Logger {
+	if logger, have := c.Get(slogLoggerKey); have {
+		return logger.(*slog.Logger)
+	}
+	return slog.Default()
+}
+
+// SetLogger replaces the request-scoped logger, so middlewares can enrich it
+// with attributes for the following handlers.
+func (c *Context) SetLogger(logger *slog.Logger) {
+	c.Set(slogLoggerKey, logger)
+}
+
+// SlogRecovery recovers from panics like RecoveryWithConfig but logs them
+// through the request-scoped logger. conf.Output is ignored.
+func SlogRecovery(conf RecoveryConfig) RouteHandler {
+	return recoveryHandler(conf, func(c *Context, err interface{}, request string, stack []byte) {
+		if stack == nil {
+			c.Logger().Warn("connection closed by client", slog.Any("error", err), slog.String("request", request))
+			return
+		}
+		c.Logger().Error("panic recovered", slog.Any("error", err), slog.String("request", request), slog.String("stack", string(stack)))
+	})
+}
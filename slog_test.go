@@ -0,0 +1,97 @@
+//go:build go1.21
+// +build go1.21
+
+package tgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeSlogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]interface{}{}
+		assertNil(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSlogMiddleware(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	r := NewRouteGroup()
+	r.Use(RequestID())
+	r.Use(SlogMiddleware(logger, "/health"))
+	r.Use(func(c *Context) {
+		c.SetLogger(c.Logger().With("tenant", "acme"))
+	})
+	r.Get("/user/", func(c *Context) {
+		c.Logger().Info("loading user")
+		c.String(404, "no user")
+	})
+	r.Get("/health", func(c *Context) {
+		c.String(200, "ok")
+	})
+	req := httptest.NewRequest("GET", "/user/42", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	records := decodeSlogRecords(t, buf)
+	assertEqual(t, 2, len(records))
+	assertEqual(t, "loading user", records[0]["msg"])
+	assertEqual(t, "acme", records[0]["tenant"])
+	assertEqual(t, "req-1", records[0]["request_id"])
+
+	record := records[1]
+	assertEqual(t, "request", record["msg"])
+	assertEqual(t, "WARN", record["level"])
+	assertEqual(t, "GET", record["method"])
+	assertEqual(t, "/user/42", record["path"])
+	assertEqual(t, "/user/", record["route"])
+	assertEqual(t, float64(404), record["status"])
+	assertEqual(t, float64(7), record["bytes"])
+	assertEqual(t, "req-1", record["request_id"])
+	assertNotNil(t, record["latency"])
+
+	buf.Reset()
+	processRequest(r, "GET", "/health")
+	assertEqual(t, "", buf.String())
+}
+
+func TestContextLoggerDefault(t *testing.T) {
+	c := createTestContext(getRequest("/", ""))
+	assertTrue(t, c.Logger() == slog.Default())
+}
+
+func TestSlogRecovery(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	r := NewRouteGroup()
+	r.Use(SlogMiddleware(logger))
+	r.Use(SlogRecovery(RecoveryConfig{}))
+	r.Get("/panic", func(c *Context) {
+		panic("boom")
+	})
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set("Authorization", "secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, http.StatusInternalServerError, w.Code)
+
+	records := decodeSlogRecords(t, buf)
+	assertEqual(t, 2, len(records))
+	assertEqual(t, "panic recovered", records[0]["msg"])
+	assertEqual(t, "ERROR", records[0]["level"])
+	assertEqual(t, "boom", records[0]["error"])
+	assertTrue(t, strings.Contains(records[0]["request"].(string), "Authorization: *"))
+	assertTrue(t, strings.Contains(records[0]["stack"].(string), "slog_test.go"))
+	assertEqual(t, "ERROR", records[1]["level"])
+	assertEqual(t, float64(500), records[1]["status"])
+}
@@ -0,0 +1,75 @@
+package tgin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/blacktear23/tgin/sse"
+)
+
+func (c *Context) sseHeaders() {
+	hdr := c.Writer.Header()
+	hdr.Set("Content-Type", sse.ContentType)
+	if hdr.Get("Cache-Control") == "" {
+		hdr.Set("Cache-Control", "no-cache")
+	}
+	if hdr.Get("Connection") == "" {
+		hdr.Set("Connection", "keep-alive")
+	}
+	hdr.Del("Content-Length")
+}
+
+func (c *Context) sseFlush() {
+	if f, ok := c.Writer.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (c *Context) SSEvent(name string, message interface{}) {
+	c.sseHeaders()
+	sse.Encode(c.Writer, sse.Event{
+		Event: name,
+		Data:  message,
+	})
+	c.sseFlush()
+}
+
+// SSEStream sends every event received from events until the channel is
+// closed or the client goes away, writing a heartbeat comment whenever the
+// stream is idle for heartbeat. It returns true if the client disconnected.
+func (c *Context) SSEStream(heartbeat time.Duration, events <-chan sse.Event) bool {
+	c.sseHeaders()
+	c.Writer.WriteHeader(200)
+	c.sseFlush()
+	var timer *time.Timer
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		timer = time.NewTimer(heartbeat)
+		defer timer.Stop()
+		tick = timer.C
+	}
+	done := c.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		case <-tick:
+			sse.Comment(c.Writer, "heartbeat")
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			sse.Encode(c.Writer, event)
+		}
+		c.sseFlush()
+		if timer != nil {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(heartbeat)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const ContentType = "text/event-stream"
+
+var fieldReplacer = strings.NewReplacer("\n", "\\n", "\r", "\\r")
+
+type Event struct {
+	ID    string
+	Event string
+	Retry uint
+	Data  interface{}
+}
+
+// Encode writes the event in text/event-stream framing. Strings and byte
+// slices are sent as is, every other value is encoded as JSON. Multi-line data
+// is split into one data field per line.
+func Encode(w io.Writer, event Event) error {
+	buf := bytes.NewBuffer(nil)
+	if event.ID != "" {
+		buf.WriteString("id: ")
+		buf.WriteString(fieldReplacer.Replace(event.ID))
+		buf.WriteByte('\n')
+	}
+	if event.Event != "" {
+		buf.WriteString("event: ")
+		buf.WriteString(fieldReplacer.Replace(event.Event))
+		buf.WriteByte('\n')
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(buf, "retry: %d\n", event.Retry)
+	}
+	data, err := encodeData(event.Data)
+	if err != nil {
+		return err
+	}
+	writeLines(buf, "data: ", data)
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// Comment writes a comment line, which clients ignore. It is useful as a
+// heartbeat to keep idle connections open through proxies.
+func Comment(w io.Writer, text string) error {
+	buf := bytes.NewBuffer(nil)
+	writeLines(buf, ": ", text)
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func encodeData(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	ret, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(ret), nil
+}
+
+func writeLines(buf *bytes.Buffer, prefix, text string) {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString(prefix)
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+}
@@ -0,0 +1,48 @@
+package sse
+
+import (
+	"bytes"
+	"testing"
+)
+
+func assertEncode(t *testing.T, event Event, expect string) {
+	buf := bytes.NewBuffer(nil)
+	err := Encode(buf, event)
+	if err != nil {
+		t.Fatalf("Encode got error: %v", err)
+	}
+	if buf.String() != expect {
+		t.Fatalf("Expect: %q but got: %q", expect, buf.String())
+	}
+}
+
+func TestEncodeString(t *testing.T) {
+	assertEncode(t, Event{Data: "hello"}, "data: hello\n\n")
+	assertEncode(t, Event{Event: "message", Data: []byte("hello")}, "event: message\ndata: hello\n\n")
+}
+
+func TestEncodeAllFields(t *testing.T) {
+	event := Event{
+		ID:    "42",
+		Event: "progress",
+		Retry: 3000,
+		Data:  map[string]int{"percent": 50},
+	}
+	assertEncode(t, event, "id: 42\nevent: progress\nretry: 3000\ndata: {\"percent\":50}\n\n")
+}
+
+func TestEncodeMultiLine(t *testing.T) {
+	assertEncode(t, Event{Data: "line1\nline2\r\nline3"}, "data: line1\ndata: line2\ndata: line3\n\n")
+}
+
+func TestEncodeSanitizeFields(t *testing.T) {
+	assertEncode(t, Event{ID: "1\n2", Event: "a\nb"}, "id: 1\\n2\nevent: a\\nb\ndata: \n\n")
+}
+
+func TestComment(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	Comment(buf, "heartbeat")
+	if buf.String() != ": heartbeat\n\n" {
+		t.Fatalf("Comment not correct: %q", buf.String())
+	}
+}
@@ -0,0 +1,82 @@
+package tgin
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blacktear23/tgin/sse"
+)
+
+func TestSSEvent(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/events", func(c *Context) {
+		c.SSEvent("message", "hello")
+		c.SSEvent("progress", H{"percent": 100})
+	})
+	req := httptest.NewRequest("GET", "/events", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assertEqual(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assertEqual(t, "no-cache", w.Header().Get("Cache-Control"))
+	assertTrue(t, w.Flushed, "Response not flushed")
+	assertEqual(t, "event: message\ndata: hello\n\nevent: progress\ndata: {\"percent\":100}\n\n", w.Body.String())
+}
+
+func TestSSEStream(t *testing.T) {
+	r := NewRouteGroup()
+	clientGone := make(chan bool, 1)
+	r.Get("/events", func(c *Context) {
+		events := make(chan sse.Event, 2)
+		events <- sse.Event{ID: "1", Data: "first"}
+		events <- sse.Event{ID: "2", Data: "second"}
+		clientGone <- c.SSEStream(50*time.Millisecond, events)
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	cctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", server.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req.WithContext(cctx))
+	assertNil(t, err)
+	reader := bufio.NewReader(resp.Body)
+	lines := []string{}
+	for len(lines) < 7 {
+		line, err := reader.ReadString('\n')
+		assertNil(t, err)
+		lines = append(lines, line)
+	}
+	assertEqual(t, []string{"id: 1\n", "data: first\n", "\n", "id: 2\n", "data: second\n", "\n", ": heartbeat\n"}, lines)
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case gone := <-clientGone:
+		assertTrue(t, gone, "Client should gone")
+	case <-time.After(5 * time.Second):
+		t.Fatal("SSEStream not stopped after client disconnect")
+	}
+}
+
+func TestSSEStreamHeartbeatOnlyWhenIdle(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/events", func(c *Context) {
+		events := make(chan sse.Event)
+		go func() {
+			for i := 0; i < 8; i++ {
+				time.Sleep(20 * time.Millisecond)
+				events <- sse.Event{Data: i}
+			}
+			close(events)
+		}()
+		c.SSEStream(100*time.Millisecond, events)
+	})
+	resp := processRequest(r, "GET", "/events")
+	body := ReadBodyString(resp)
+	assertEqual(t, 8, strings.Count(body, "data: "), body)
+	assertFalse(t, strings.Contains(body, "heartbeat"), body)
+}
@@ -0,0 +1,185 @@
+package trace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+const (
+	FlagSampled byte = 0x01
+
+	maxTracestateLength  = 512
+	maxTracestateMembers = 32
+)
+
+var ErrInvalidTraceparent = errors.New("trace: invalid traceparent")
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+func NewTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		randomFill(id[:])
+	}
+	return id
+}
+
+func NewSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		randomFill(id[:])
+	}
+	return id
+}
+
+func randomFill(buf []byte) {
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+}
+
+// SpanContext is the part of a span propagated between services.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent formats the context as a version 00 traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// Inject sets the traceparent and tracestate headers for an outgoing request.
+func (sc SpanContext) Inject(header http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+	header.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		header.Set(TracestateHeader, sc.TraceState)
+	} else {
+		header.Del(TracestateHeader)
+	}
+}
+
+// Extract reads the remote span context from the traceparent and tracestate
+// headers. The tracestate is dropped when the traceparent is missing or
+// invalid.
+func Extract(header http.Header) (SpanContext, error) {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, err
+	}
+	sc.TraceState = ParseTracestate(strings.Join(header.Values(TracestateHeader), ","))
+	return sc, nil
+}
+
+// ParseTraceparent parses a traceparent header value. Versions newer than 00
+// are accepted as long as their first four fields have the version 00 layout.
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+	value = strings.TrimSpace(value)
+	if len(value) < 55 {
+		return sc, ErrInvalidTraceparent
+	}
+	version, ok := decodeHex(value[0:2])
+	if !ok || version[0] == 0xff || value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+	if len(value) > 55 && (version[0] == 0 || value[55] != '-') {
+		return sc, ErrInvalidTraceparent
+	}
+	traceID, ok := decodeHex(value[3:35])
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+	spanID, ok := decodeHex(value[36:52])
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+	flags, ok := decodeHex(value[53:55])
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// decodeHex only accepts lowercase hex digits as the specification requires.
+func decodeHex(s string) ([]byte, bool) {
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch < '0' || ch > '9') && (ch < 'a' || ch > 'f') {
+			return nil, false
+		}
+	}
+	data, err := hex.DecodeString(s)
+	return data, err == nil
+}
+
+// ParseTracestate normalizes a tracestate header value. Empty members are
+// removed, and the whole value is dropped when it has invalid members, more
+// than 32 members or is longer than 512 characters.
+func ParseTracestate(value string) string {
+	members := make([]string, 0, 4)
+	for _, member := range strings.Split(value, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		eq := strings.IndexByte(member, '=')
+		if eq <= 0 || eq == len(member)-1 || strings.ContainsAny(member, " \t") {
+			return ""
+		}
+		members = append(members, member)
+	}
+	if len(members) > maxTracestateMembers {
+		return ""
+	}
+	state := strings.Join(members, ",")
+	if len(state) > maxTracestateLength {
+		return ""
+	}
+	return state
+}
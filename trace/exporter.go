@@ -0,0 +1,71 @@
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+type SpanExporter interface {
+	ExportSpan(span SpanData)
+}
+
+// WriterExporter writes every span as one JSON object per line.
+type WriterExporter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func NewWriterExporter(out io.Writer) *WriterExporter {
+	return &WriterExporter{out: out}
+}
+
+func NewStdoutExporter() *WriterExporter {
+	return NewWriterExporter(os.Stdout)
+}
+
+func (e *WriterExporter) ExportSpan(span SpanData) {
+	data, err := json.Marshal(span)
+	if err != nil {
+		log.Printf("[Trace] cannot encode span %s: %v", span.Name, err)
+		return
+	}
+	data = append(data, '\n')
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := e.out.Write(data); err != nil {
+		log.Printf("[Trace] cannot export span %s: %v", span.Name, err)
+	}
+}
+
+// InMemoryExporter keeps exported spans, mostly for tests.
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+func (e *InMemoryExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	e.mu.Unlock()
+}
+
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	spans := make([]SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	e.spans = nil
+	e.mu.Unlock()
+}
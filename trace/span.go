@@ -0,0 +1,116 @@
+package trace
+
+import (
+	"sync"
+	"time"
+)
+
+// SpanData is the immutable record of an ended span handed to exporters.
+type SpanData struct {
+	Name         string                 `json:"name"`
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	Duration     time.Duration          `json:"duration"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+type Span struct {
+	mu         sync.Mutex
+	name       string
+	sc         SpanContext
+	parent     SpanID
+	start      time.Time
+	attributes map[string]interface{}
+	err        string
+	ended      bool
+	exporter   SpanExporter
+}
+
+// Start begins a span. With a valid parent the span joins the parent's trace
+// and inherits its flags and tracestate, otherwise it starts a new sampled
+// trace. Only sampled spans are exported, and a nil exporter drops them.
+func Start(name string, parent SpanContext, exporter SpanExporter) *Span {
+	span := &Span{
+		name:       name,
+		start:      time.Now(),
+		attributes: map[string]interface{}{},
+		exporter:   exporter,
+	}
+	if parent.IsValid() {
+		span.sc = SpanContext{
+			TraceID:    parent.TraceID,
+			Flags:      parent.Flags,
+			TraceState: parent.TraceState,
+		}
+		span.parent = parent.SpanID
+	} else {
+		span.sc = SpanContext{TraceID: NewTraceID(), Flags: FlagSampled}
+	}
+	span.sc.SpanID = NewSpanID()
+	return span
+}
+
+// StartChild begins a span in the same trace with s as its parent.
+func (s *Span) StartChild(name string) *Span {
+	return Start(name, s.sc, s.exporter)
+}
+
+func (s *Span) SpanContext() SpanContext {
+	return s.sc
+}
+
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	s.attributes[key] = value
+	s.mu.Unlock()
+}
+
+func (s *Span) SetError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.err = err.Error()
+	s.mu.Unlock()
+}
+
+// End records the span duration and exports it. Later calls are ignored.
+func (s *Span) End() {
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Name:       s.name,
+		TraceID:    s.sc.TraceID.String(),
+		SpanID:     s.sc.SpanID.String(),
+		Start:      s.start,
+		End:        end,
+		Duration:   end.Sub(s.start),
+		Attributes: make(map[string]interface{}, len(s.attributes)),
+		Error:      s.err,
+	}
+	for key, val := range s.attributes {
+		data.Attributes[key] = val
+	}
+	if s.parent.IsValid() {
+		data.ParentSpanID = s.parent.String()
+	}
+	s.mu.Unlock()
+	if s.exporter != nil && s.sc.IsSampled() {
+		s.exporter.ExportSpan(data)
+	}
+}
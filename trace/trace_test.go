@@ -0,0 +1,130 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+const validTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent(validTraceparent)
+	if err != nil {
+		t.Fatalf("ParseTraceparent got error: %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("Unexpected ids: %s %s", sc.TraceID, sc.SpanID)
+	}
+	if !sc.IsSampled() {
+		t.Fatal("Expect sampled flag")
+	}
+	if sc.Traceparent() != validTraceparent {
+		t.Fatalf("Expect: %s but got: %s", validTraceparent, sc.Traceparent())
+	}
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Fatalf("Future version should be accepted: %v", err)
+	}
+}
+
+func TestParseInvalidTraceparent(t *testing.T) {
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	for _, value := range invalid {
+		if _, err := ParseTraceparent(value); err != ErrInvalidTraceparent {
+			t.Fatalf("Expect %q to be invalid, got: %v", value, err)
+		}
+	}
+}
+
+func TestParseTracestate(t *testing.T) {
+	if state := ParseTracestate("congo=t61rcWkgMzE, ,rojo=00f067aa0ba902b7"); state != "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7" {
+		t.Fatalf("Unexpected tracestate: %q", state)
+	}
+	if state := ParseTracestate("congo=t61rcWkgMzE,invalid"); state != "" {
+		t.Fatalf("Invalid tracestate should be dropped: %q", state)
+	}
+}
+
+func TestExtractInject(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceparentHeader, validTraceparent)
+	header.Add(TracestateHeader, "congo=t61rcWkgMzE")
+	header.Add(TracestateHeader, "rojo=00f067aa0ba902b7")
+	sc, err := Extract(header)
+	if err != nil {
+		t.Fatalf("Extract got error: %v", err)
+	}
+	if sc.TraceState != "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7" {
+		t.Fatalf("Unexpected tracestate: %q", sc.TraceState)
+	}
+
+	out := http.Header{}
+	sc.Inject(out)
+	if out.Get(TraceparentHeader) != validTraceparent || out.Get(TracestateHeader) != sc.TraceState {
+		t.Fatalf("Unexpected injected headers: %v", out)
+	}
+}
+
+func TestSpanExport(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	parent, _ := ParseTraceparent(validTraceparent)
+	span := Start("GET /users", parent, exporter)
+	child := span.StartChild("db query")
+	child.SetAttribute("rows", 3)
+	child.SetError(errors.New("timeout"))
+	child.End()
+	span.End()
+	span.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expect 2 spans but got: %d", len(spans))
+	}
+	if spans[0].Name != "db query" || spans[0].ParentSpanID != span.SpanContext().SpanID.String() {
+		t.Fatalf("Unexpected child span: %+v", spans[0])
+	}
+	if spans[0].Attributes["rows"] != 3 || spans[0].Error != "timeout" {
+		t.Fatalf("Unexpected child span: %+v", spans[0])
+	}
+	if spans[1].TraceID != parent.TraceID.String() || spans[1].ParentSpanID != parent.SpanID.String() {
+		t.Fatalf("Span should continue parent trace: %+v", spans[1])
+	}
+
+	exporter.Reset()
+	if len(exporter.Spans()) != 0 {
+		t.Fatal("Reset should drop spans")
+	}
+}
+
+func TestUnsampledSpanNotExported(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	Start("ignored", parent, exporter).End()
+	if len(exporter.Spans()) != 0 {
+		t.Fatal("Unsampled span should not be exported")
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	span := Start("job", SpanContext{}, NewWriterExporter(buf))
+	span.End()
+	var data SpanData
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		t.Fatalf("Cannot decode span: %v", err)
+	}
+	if data.Name != "job" || data.TraceID != span.SpanContext().TraceID.String() || data.ParentSpanID != "" {
+		t.Fatalf("Unexpected span: %+v", data)
+	}
+}
@@ -0,0 +1,82 @@
+package tgin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/blacktear23/tgin/trace"
+)
+
+const traceSpanKey = "tgin.trace.span"
+
+type TracingConfig struct {
+	// Exporter receives the ended spans. Nil drops them.
+	Exporter trace.SpanExporter
+
+	// SkipPaths are request paths for which no span is started.
+	SkipPaths []string
+}
+
+func Tracing(exporter trace.SpanExporter) RouteHandler {
+	return TracingWithConfig(TracingConfig{Exporter: exporter})
+}
+
+// TracingWithConfig starts a span per request, continuing the trace from the
+// traceparent and tracestate headers when they are valid. The span is named
+// after the method and the matched route pattern and records the status.
+// Handlers reach it through c.Span() and c.StartSpan().
+func TracingWithConfig(conf TracingConfig) RouteHandler {
+	skip := skipPathSet(conf.SkipPaths)
+	return func(c *Context) {
+		r := c.Request
+		if skip[r.URL.Path] {
+			c.Next()
+			return
+		}
+		parent, _ := trace.Extract(r.Header)
+		span := trace.Start(r.Method, parent, conf.Exporter)
+		defer span.End()
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.target", r.URL.RequestURI())
+		span.SetAttribute("net.peer.ip", c.ClientIP())
+		if id := c.RequestID(); id != "" {
+			span.SetAttribute("request_id", id)
+		}
+		c.Set(traceSpanKey, span)
+		c.Next()
+		if route := c.FullPath(); route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttribute("http.route", route)
+		}
+		status := 200
+		if ww, ok := unwrapResponseWriter(c.Writer); ok {
+			status = ww.Status()
+			span.SetAttribute("http.response_size", ww.Size())
+		}
+		span.SetAttribute("http.status_code", status)
+		if err := c.Errors.Last(); err != nil {
+			span.SetError(err)
+		} else if status >= 500 {
+			span.SetError(fmt.Errorf("%d %s", status, http.StatusText(status)))
+		}
+	}
+}
+
+// Span returns the request span started by the Tracing middleware, or nil
+// without it.
+func (c *Context) Span() *trace.Span {
+	if span, have := c.Get(traceSpanKey); have {
+		return span.(*trace.Span)
+	}
+	return nil
+}
+
+// StartSpan begins a child of the request span. Without the Tracing
+// middleware the span starts a new trace and is not exported. Callers must
+// End it.
+func (c *Context) StartSpan(name string) *trace.Span {
+	if span := c.Span(); span != nil {
+		return span.StartChild(name)
+	}
+	return trace.Start(name, trace.SpanContext{}, nil)
+}
@@ -0,0 +1,72 @@
+package tgin
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blacktear23/tgin/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	exporter := trace.NewInMemoryExporter()
+	r := NewRouteGroup()
+	r.Use(Tracing(exporter))
+	r.Get("/users/", func(c *Context) {
+		span := c.StartSpan("load user")
+		span.End()
+		c.String(200, "ok")
+	})
+	req := httptest.NewRequest("GET", "/users/42?full=1", nil)
+	req.Header.Set(trace.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(trace.TracestateHeader, "congo=t61rcWkgMzE")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.Spans()
+	assertEqual(t, 2, len(spans))
+	child, root := spans[0], spans[1]
+	assertEqual(t, "load user", child.Name)
+	assertEqual(t, root.SpanID, child.ParentSpanID)
+	assertEqual(t, "GET /users/", root.Name)
+	assertEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", root.TraceID)
+	assertEqual(t, "00f067aa0ba902b7", root.ParentSpanID)
+	assertEqual(t, "/users/", root.Attributes["http.route"])
+	assertEqual(t, "/users/42?full=1", root.Attributes["http.target"])
+	assertEqual(t, 200, root.Attributes["http.status_code"])
+	assertEqual(t, 2, root.Attributes["http.response_size"])
+	assertEqual(t, "", root.Error)
+}
+
+func TestTracingErrors(t *testing.T) {
+	exporter := trace.NewInMemoryExporter()
+	r := NewRouteGroup()
+	r.Use(RequestID(), Tracing(exporter))
+	r.Get("/fail", func(c *Context) {
+		c.Error(errors.New("db down"))
+		c.AbortWithStatus(503)
+	})
+	r.Get("/broken", func(c *Context) {
+		c.AbortWithStatus(500)
+	})
+	processRequest(r, "GET", "/fail")
+	processRequest(r, "GET", "/broken")
+	processRequest(r, "GET", "/missing")
+
+	spans := exporter.Spans()
+	assertEqual(t, 3, len(spans))
+	assertEqual(t, "db down", spans[0].Error)
+	assertEqual(t, 503, spans[0].Attributes["http.status_code"])
+	assertEqual(t, 26, len(spans[0].Attributes["request_id"].(string)))
+	assertEqual(t, 32, len(spans[0].TraceID))
+	assertEqual(t, "", spans[0].ParentSpanID)
+	assertEqual(t, "500 Internal Server Error", spans[1].Error)
+	assertEqual(t, "GET", spans[2].Name)
+}
+
+func TestStartSpanWithoutTracing(t *testing.T) {
+	c := createTestContext(getRequest("/", ""))
+	assertTrue(t, c.Span() == nil)
+	span := c.StartSpan("orphan")
+	assertTrue(t, span.SpanContext().IsValid())
+	span.End()
+}
@@ -0,0 +1,164 @@
+package tgin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const sniffLen = 512
+
+var (
+	ErrFileTooLarge          = errors.New("upload file too large")
+	ErrContentTypeNotAllowed = errors.New("upload content type not allowed")
+)
+
+func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return saveToFile(src, dst)
+}
+
+func saveToFile(src io.Reader, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, src)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
+
+type MultipartOptions struct {
+	// MaxFileSize limits the size of each file part. Zero means no limit.
+	MaxFileSize int64
+
+	// AllowedContentTypes lists accepted file content types, checked against
+	// the type sniffed from the first 512 bytes and against the declared part
+	// type. Entries like "video/*" match a whole type. Empty means every type
+	// is accepted.
+	AllowedContentTypes []string
+}
+
+func (o MultipartOptions) allowContentType(contentType string) bool {
+	if len(o.AllowedContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range o.AllowedContentTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mediaType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+type MultipartPart struct {
+	*multipart.Part
+	// ContentType is sniffed from the content of file parts.
+	ContentType string
+	reader      io.Reader
+}
+
+func (p *MultipartPart) IsFile() bool {
+	return p.FileName() != ""
+}
+
+func (p *MultipartPart) Read(data []byte) (int, error) {
+	return p.reader.Read(data)
+}
+
+func (p *MultipartPart) SaveTo(dst string) error {
+	return saveToFile(p, dst)
+}
+
+type limitedPartReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedPartReader) Read(data []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrFileTooLarge
+	}
+	if int64(len(data)) > l.remaining+1 {
+		data = data[:l.remaining+1]
+	}
+	n, err := l.r.Read(data)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n - int(-l.remaining), ErrFileTooLarge
+	}
+	return n, err
+}
+
+// StreamMultipart reads a multipart body part by part without buffering it in
+// memory or temporary files. File parts are checked against opts before the
+// handler sees them, and reading past MaxFileSize returns ErrFileTooLarge.
+func (c *Context) StreamMultipart(opts MultipartOptions, handler func(part *MultipartPart) error) error {
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		mp := &MultipartPart{
+			Part:   part,
+			reader: part,
+		}
+		if mp.IsFile() {
+			br := bufio.NewReaderSize(part, sniffLen)
+			head, _ := br.Peek(sniffLen)
+			mp.ContentType = http.DetectContentType(head)
+			declared := part.Header.Get("Content-Type")
+			if !opts.allowContentType(mp.ContentType) {
+				part.Close()
+				return fmt.Errorf("%w: %s has type %s", ErrContentTypeNotAllowed, mp.FileName(), mp.ContentType)
+			}
+			if declared != "" && declared != "application/octet-stream" && !opts.allowContentType(declared) {
+				part.Close()
+				return fmt.Errorf("%w: %s is declared as %s", ErrContentTypeNotAllowed, mp.FileName(), declared)
+			}
+			mp.reader = br
+			if opts.MaxFileSize > 0 {
+				mp.reader = &limitedPartReader{r: br, remaining: opts.MaxFileSize}
+			}
+		}
+		err = handler(mp)
+		part.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package tgin
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mp4Data starts with an ftyp box, so it is sniffed as video/mp4.
+const mp4Data = "\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"
+
+type uploadFile struct {
+	field       string
+	name        string
+	contentType string
+	content     string
+}
+
+func uploadRequest(fields map[string]string, files ...uploadFile) *http.Request {
+	body := bytes.NewBuffer(nil)
+	mpw := multipart.NewWriter(body)
+	for key, value := range fields {
+		mpw.WriteField(key, value)
+	}
+	for _, file := range files {
+		hdr := textproto.MIMEHeader{}
+		hdr.Set("Content-Disposition", `form-data; name="`+file.field+`"; filename="`+file.name+`"`)
+		if file.contentType != "" {
+			hdr.Set("Content-Type", file.contentType)
+		}
+		fw, _ := mpw.CreatePart(hdr)
+		fw.Write([]byte(file.content))
+	}
+	mpw.Close()
+	req := httptest.NewRequest("POST", "/upload", body)
+	req.Header.Set("Content-Type", mpw.FormDataContentType())
+	return req
+}
+
+func TestSaveUploadedFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tgin-upload")
+	defer os.RemoveAll(dir)
+	req := uploadRequest(nil, uploadFile{"file", "a.txt", "text/plain", "content a"})
+	ctx := createTestContext(req)
+	fh, err := ctx.FormFile("file")
+	assertNil(t, err)
+	dst := filepath.Join(dir, "sub", fh.Filename)
+	assertNil(t, ctx.SaveUploadedFile(fh, dst))
+	data, _ := ioutil.ReadFile(dst)
+	assertEqual(t, "content a", string(data))
+}
+
+func TestMultipartForm(t *testing.T) {
+	req := uploadRequest(map[string]string{"title": "hello"},
+		uploadFile{"files", "a.txt", "text/plain", "a"},
+		uploadFile{"files", "b.txt", "text/plain", "b"})
+	ctx := createTestContext(req)
+	form, err := ctx.MultipartForm()
+	assertNil(t, err)
+	assertEqual(t, []string{"hello"}, form.Value["title"])
+	assertEqual(t, 2, len(form.File["files"]))
+	assertEqual(t, "b.txt", form.File["files"][1].Filename)
+}
+
+func TestStreamMultipart(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tgin-upload")
+	defer os.RemoveAll(dir)
+	req := uploadRequest(map[string]string{"title": "movie"},
+		uploadFile{"video", "clip.mp4", "video/mp4", mp4Data})
+	ctx := createTestContext(req)
+	fields := map[string]string{}
+	err := ctx.StreamMultipart(MultipartOptions{
+		MaxFileSize:         int64(len(mp4Data)),
+		AllowedContentTypes: []string{"video/*"},
+	}, func(part *MultipartPart) error {
+		if !part.IsFile() {
+			data, _ := ioutil.ReadAll(part)
+			fields[part.FormName()] = string(data)
+			return nil
+		}
+		assertEqual(t, "video/mp4", part.ContentType)
+		return part.SaveTo(filepath.Join(dir, part.FileName()))
+	})
+	assertNil(t, err)
+	assertEqual(t, "movie", fields["title"])
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "clip.mp4"))
+	assertEqual(t, mp4Data, string(data))
+}
+
+func TestStreamMultipartTooLarge(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tgin-upload")
+	defer os.RemoveAll(dir)
+	req := uploadRequest(nil, uploadFile{"video", "clip.mp4", "video/mp4", "video data too large"})
+	ctx := createTestContext(req)
+	dst := filepath.Join(dir, "clip.mp4")
+	err := ctx.StreamMultipart(MultipartOptions{MaxFileSize: 10}, func(part *MultipartPart) error {
+		return part.SaveTo(dst)
+	})
+	assertTrue(t, errors.Is(err, ErrFileTooLarge), "Error should be ErrFileTooLarge")
+	_, err = os.Stat(dst)
+	assertTrue(t, os.IsNotExist(err), "Partial file should be removed")
+}
+
+func TestStreamMultipartContentType(t *testing.T) {
+	req := uploadRequest(nil, uploadFile{"file", "page.html", "", "<html><body>hi</body></html>"})
+	ctx := createTestContext(req)
+	err := ctx.StreamMultipart(MultipartOptions{
+		AllowedContentTypes: []string{"video/*", "image/png"},
+	}, func(part *MultipartPart) error {
+		return nil
+	})
+	assertTrue(t, errors.Is(err, ErrContentTypeNotAllowed), "Error should be ErrContentTypeNotAllowed")
+}
+
+func TestStreamMultipartSniffsDeclaredType(t *testing.T) {
+	opts := MultipartOptions{AllowedContentTypes: []string{"video/*"}}
+	req := uploadRequest(nil, uploadFile{"video", "clip.mp4", "video/mp4", "<html><body>hi</body></html>"})
+	err := createTestContext(req).StreamMultipart(opts, func(part *MultipartPart) error {
+		return nil
+	})
+	assertTrue(t, errors.Is(err, ErrContentTypeNotAllowed), "Sniffed type should be checked")
+
+	req = uploadRequest(nil, uploadFile{"video", "clip.mp4", "text/html", mp4Data})
+	err = createTestContext(req).StreamMultipart(opts, func(part *MultipartPart) error {
+		return nil
+	})
+	assertTrue(t, errors.Is(err, ErrContentTypeNotAllowed), "Declared type should be checked")
+}
@@ -1,24 +1,284 @@
 package tgin
 
 import (
+	"bufio"
+	"io"
+	"net"
 	"net/http"
 )
 
 var (
-	_ http.ResponseWriter = (*ResponseWriterWrapper)(nil)
-	_ http.Hijacker       = (*ResponseWriterWrapper)(nil)
+	_ ResponseWriter     = (*ResponseWriterWrapper)(nil)
+	_ http.Flusher       = flushWriter{}
+	_ http.CloseNotifier = closeNotifyWriter{}
+	_ http.Hijacker      = hijackWriter{}
+	_ http.Pusher        = pushWriter{}
 )
 
 type H map[string]interface{}
 
+// ResponseWriter is implemented by Context.Writer. It also implements
+// http.Flusher, http.CloseNotifier, http.Hijacker and http.Pusher when the
+// underlying writer does.
+type ResponseWriter interface {
+	http.ResponseWriter
+	io.ReaderFrom
+
+	// Status returns the HTTP status code of the response.
+	Status() int
+
+	// Size returns the number of bytes written to the response body.
+	Size() int
+
+	// Written reports whether the status code has been sent.
+	Written() bool
+
+	// WriteHeaderNow sends the status code if it has not been sent yet.
+	WriteHeaderNow()
+}
+
 type ResponseWriterWrapper struct {
 	http.ResponseWriter
-	http.Hijacker
-	code int
-	ctx  *Context
+	code          int
+	size          int
+	written       bool
+	ctx           *Context
+	headerHandler []func()
+}
+
+type writerWrapper interface {
+	wrapper() *ResponseWriterWrapper
+}
+
+type flushWriter struct {
+	w *ResponseWriterWrapper
+}
+
+type closeNotifyWriter struct {
+	w *ResponseWriterWrapper
+}
+
+type hijackWriter struct {
+	w *ResponseWriterWrapper
+}
+
+type pushWriter struct {
+	w *ResponseWriterWrapper
+}
+
+const (
+	flushFeature = 1 << iota
+	closeNotifyFeature
+	hijackFeature
+	pushFeature
+)
+
+// wrapResponseWriter returns a writer which implements http.Flusher,
+// http.CloseNotifier, http.Hijacker and http.Pusher only when w does, so type
+// assertions on Context.Writer keep reporting what the connection really
+// supports.
+func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *ResponseWriterWrapper) {
+	ww := &ResponseWriterWrapper{
+		ResponseWriter: w,
+		code:           200,
+	}
+	features := 0
+	if _, ok := w.(http.Flusher); ok {
+		features |= flushFeature
+	}
+	if _, ok := w.(http.CloseNotifier); ok {
+		features |= closeNotifyFeature
+	}
+	if _, ok := w.(http.Hijacker); ok {
+		features |= hijackFeature
+	}
+	if _, ok := w.(http.Pusher); ok {
+		features |= pushFeature
+	}
+	f, n, h, p := flushWriter{ww}, closeNotifyWriter{ww}, hijackWriter{ww}, pushWriter{ww}
+	switch features {
+	case flushFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Flusher
+		}{ww, f}, ww
+	case closeNotifyFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.CloseNotifier
+		}{ww, n}, ww
+	case flushFeature | closeNotifyFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Flusher
+			http.CloseNotifier
+		}{ww, f, n}, ww
+	case hijackFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Hijacker
+		}{ww, h}, ww
+	case hijackFeature | flushFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Hijacker
+			http.Flusher
+		}{ww, h, f}, ww
+	case hijackFeature | closeNotifyFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Hijacker
+			http.CloseNotifier
+		}{ww, h, n}, ww
+	case hijackFeature | flushFeature | closeNotifyFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Hijacker
+			http.Flusher
+			http.CloseNotifier
+		}{ww, h, f, n}, ww
+	case pushFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Pusher
+		}{ww, p}, ww
+	case pushFeature | flushFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Pusher
+			http.Flusher
+		}{ww, p, f}, ww
+	case pushFeature | closeNotifyFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Pusher
+			http.CloseNotifier
+		}{ww, p, n}, ww
+	case pushFeature | flushFeature | closeNotifyFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Pusher
+			http.Flusher
+			http.CloseNotifier
+		}{ww, p, f, n}, ww
+	case pushFeature | hijackFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Pusher
+			http.Hijacker
+		}{ww, p, h}, ww
+	case pushFeature | hijackFeature | flushFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Pusher
+			http.Hijacker
+			http.Flusher
+		}{ww, p, h, f}, ww
+	case pushFeature | hijackFeature | closeNotifyFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Pusher
+			http.Hijacker
+			http.CloseNotifier
+		}{ww, p, h, n}, ww
+	case pushFeature | hijackFeature | flushFeature | closeNotifyFeature:
+		return struct {
+			*ResponseWriterWrapper
+			http.Pusher
+			http.Hijacker
+			http.Flusher
+			http.CloseNotifier
+		}{ww, p, h, f, n}, ww
+	}
+	return ww, ww
+}
+
+func unwrapResponseWriter(w http.ResponseWriter) (*ResponseWriterWrapper, bool) {
+	if ww, ok := w.(writerWrapper); ok {
+		return ww.wrapper(), true
+	}
+	return nil, false
+}
+
+func (w *ResponseWriterWrapper) wrapper() *ResponseWriterWrapper {
+	return w
 }
 
 func (w *ResponseWriterWrapper) WriteHeader(code int) {
+	if w.written {
+		if code != w.code {
+			debugPrint("[WARNING] Headers were already written. Wanted to override status code %d with %d", w.code, code)
+		}
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	handlers := w.headerHandler
+	w.headerHandler = nil
+	for _, handler := range handlers {
+		handler()
+	}
 	w.code = code
+	w.written = true
 	w.ResponseWriter.WriteHeader(code)
 }
+
+func (w *ResponseWriterWrapper) WriteHeaderNow() {
+	if !w.written {
+		w.WriteHeader(w.code)
+	}
+}
+
+func (w *ResponseWriterWrapper) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *ResponseWriterWrapper) Status() int {
+	return w.code
+}
+
+func (w *ResponseWriterWrapper) Size() int {
+	return w.size
+}
+
+func (w *ResponseWriterWrapper) Written() bool {
+	return w.written
+}
+
+func (w *ResponseWriterWrapper) ReadFrom(r io.Reader) (int64, error) {
+	w.WriteHeaderNow()
+	var (
+		n   int64
+		err error
+	)
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(w.ResponseWriter, r)
+	}
+	w.size += int(n)
+	return n, err
+}
+
+func (f flushWriter) Flush() {
+	f.w.WriteHeaderNow()
+	f.w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (n closeNotifyWriter) CloseNotify() <-chan bool {
+	return n.w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (h hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.w.written = true
+	return h.w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (p pushWriter) Push(target string, opts *http.PushOptions) error {
+	return p.w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
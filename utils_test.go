@@ -0,0 +1,189 @@
+package tgin
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
+
+func TestResponseWriterWithoutHijacker(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/", func(c *Context) {
+		_, hijack := c.Writer.(http.Hijacker)
+		_, push := c.Writer.(http.Pusher)
+		assertFalse(t, hijack, "Writer should not be Hijacker")
+		assertFalse(t, push, "Writer should not be Pusher")
+		_, notify := c.Writer.(http.CloseNotifier)
+		assertFalse(t, notify, "Writer should not be CloseNotifier")
+		f, ok := c.Writer.(http.Flusher)
+		assertTrue(t, ok, "Writer should be Flusher")
+		c.Writer.Write([]byte("data"))
+		f.Flush()
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assertTrue(t, w.Flushed, "Response not flushed")
+	assertEqual(t, "data", w.Body.String())
+}
+
+// plainWriter implements only http.ResponseWriter.
+type plainWriter struct {
+	w http.ResponseWriter
+}
+
+func (p plainWriter) Header() http.Header {
+	return p.w.Header()
+}
+
+func (p plainWriter) Write(data []byte) (int, error) {
+	return p.w.Write(data)
+}
+
+func (p plainWriter) WriteHeader(code int) {
+	p.w.WriteHeader(code)
+}
+
+func TestResponseWriterWithoutFlusher(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/", func(c *Context) {
+		_, flush := c.Writer.(http.Flusher)
+		_, notify := c.Writer.(http.CloseNotifier)
+		assertFalse(t, flush, "Writer should not be Flusher")
+		assertFalse(t, notify, "Writer should not be CloseNotifier")
+		c.Stream(func(w io.Writer) bool {
+			w.Write([]byte("data"))
+			return false
+		})
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(plainWriter{w}, req)
+	assertFalse(t, w.Flushed, "Response should not be flushed")
+	assertEqual(t, "data", w.Body.String())
+}
+
+func TestResponseWriterOverConnection(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/", func(c *Context) {
+		_, flush := c.Writer.(http.Flusher)
+		_, notify := c.Writer.(http.CloseNotifier)
+		_, hijack := c.Writer.(http.Hijacker)
+		_, ok := c.Writer.(ResponseWriter)
+		assertTrue(t, flush && notify && hijack && ok, "Writer should keep the connection interfaces")
+		c.String(200, "OK")
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	assertNil(t, err)
+	assertBody(t, resp, "OK")
+}
+
+func TestResponseWriterWithHijacker(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/", func(c *Context) {
+		hj, ok := c.Writer.(http.Hijacker)
+		assertTrue(t, ok, "Writer should be Hijacker")
+		hj.Hijack()
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r.ServeHTTP(w, req)
+	assertTrue(t, w.hijacked, "Connection not hijacked")
+}
+
+func TestResponseWriterReadFrom(t *testing.T) {
+	r := NewRouteGroup()
+	r.Use(LoggerMiddleware)
+	r.Get("/", func(c *Context) {
+		_, ok := c.Writer.(io.ReaderFrom)
+		assertTrue(t, ok, "Writer should be ReaderFrom")
+		io.Copy(c.Writer, bytes.NewBufferString("copied"))
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	assertNil(t, err)
+	assertEqual(t, 200, resp.StatusCode)
+	assertBody(t, resp, "copied")
+}
+
+type countHeaderRecorder struct {
+	*httptest.ResponseRecorder
+	headerWrites int
+}
+
+func (r *countHeaderRecorder) WriteHeader(code int) {
+	r.headerWrites++
+	r.ResponseRecorder.WriteHeader(code)
+}
+
+func TestResponseWriterStatusAndSize(t *testing.T) {
+	r := NewRouteGroup()
+	var rw ResponseWriter
+	r.Get("/", func(c *Context) {
+		rw = c.ResponseWriter()
+		assertFalse(t, rw.Written())
+		assertEqual(t, 200, rw.Status())
+		c.String(201, "Hello World")
+	})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, 201, resp.StatusCode)
+	assertTrue(t, rw.Written())
+	assertEqual(t, 201, rw.Status())
+	assertEqual(t, 11, rw.Size())
+}
+
+func TestResponseWriterDuplicateWriteHeader(t *testing.T) {
+	r := NewRouteGroup()
+	r.Use(RecoveryMiddleware)
+	r.Get("/", func(c *Context) {
+		c.String(200, "partial")
+		panic("panic after write")
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	w := &countHeaderRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r.ServeHTTP(w, req)
+	assertEqual(t, 1, w.headerWrites, "WriteHeader should be called once")
+	assertEqual(t, 200, w.Code)
+}
+
+func TestContextResponseWriter(t *testing.T) {
+	ctx := createTestContext(httptest.NewRequest("GET", "/", nil))
+	rw := ctx.ResponseWriter()
+	assertEqual(t, rw, ctx.ResponseWriter())
+	ctx.String(201, "Hello")
+	assertTrue(t, rw.Written())
+	assertEqual(t, 201, rw.Status())
+	assertEqual(t, 5, rw.Size())
+	_, ok := ctx.Writer.(http.Flusher)
+	assertTrue(t, ok, "Wrapped writer should keep Flusher")
+}
+
+func TestResponseWriterWriteHeaderNow(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/", func(c *Context) {
+		c.Writer.WriteHeader(204)
+		rw := c.ResponseWriter()
+		rw.WriteHeaderNow()
+		assertTrue(t, rw.Written())
+		assertEqual(t, 0, rw.Size())
+	})
+	resp := processRequest(r, "GET", "/")
+	assertEqual(t, 204, resp.StatusCode)
+}
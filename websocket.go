@@ -0,0 +1,25 @@
+package tgin
+
+import (
+	"net/http"
+
+	"github.com/blacktear23/tgin/websocket"
+)
+
+var defaultUpgrader = &websocket.Upgrader{}
+
+func (c *Context) UpgradeWebSocket(upgraders ...*websocket.Upgrader) (*websocket.Conn, error) {
+	upgrader := defaultUpgrader
+	if len(upgraders) > 0 && upgraders[0] != nil {
+		upgrader = upgraders[0]
+	}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		c.Abort()
+		return nil, err
+	}
+	if ww, ok := unwrapResponseWriter(c.Writer); ok {
+		ww.code = http.StatusSwitchingProtocols
+	}
+	return conn, nil
+}
@@ -0,0 +1,437 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	continuationFrame = 0
+	TextMessage       = 1
+	BinaryMessage     = 2
+	CloseMessage      = 8
+	PingMessage       = 9
+	PongMessage       = 10
+)
+
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseMandatoryExtension      = 1010
+	CloseInternalServerErr       = 1011
+	CloseTLSHandshake            = 1015
+)
+
+const (
+	DefaultReadLimit      = 32 << 20 // 32 MB
+	maxControlPayloadSize = 125
+	controlWriteTimeout   = time.Second
+)
+
+var (
+	ErrReadLimit = errors.New("websocket: read limit exceeded")
+	ErrCloseSent = errors.New("websocket: close sent")
+)
+
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: close %d %s", e.Code, e.Text)
+}
+
+type protocolError struct {
+	code int
+	msg  string
+}
+
+func (e *protocolError) Error() string {
+	return "websocket: " + e.msg
+}
+
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	subprotocol string
+	readLimit   int64
+	readErr     error
+	writeMu     sync.Mutex
+	closeSent   bool
+	deadlineMu  sync.Mutex
+	deadline    time.Time
+	pingHandler func(appData string) error
+	pongHandler func(appData string) error
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, subprotocol string) *Conn {
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+	c := &Conn{
+		conn:        conn,
+		br:          br,
+		subprotocol: subprotocol,
+		readLimit:   DefaultReadLimit,
+	}
+	c.SetPingHandler(nil)
+	c.SetPongHandler(nil)
+	return c
+}
+
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// SetReadLimit sets the maximum size in bytes of a message read from the
+// peer. A limit of zero or less means DefaultReadLimit.
+func (c *Conn) SetReadLimit(limit int64) {
+	if limit <= 0 {
+		limit = DefaultReadLimit
+	}
+	c.readLimit = limit
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	c.deadline = t
+	c.deadlineMu.Unlock()
+	return c.conn.SetWriteDeadline(t)
+}
+
+func (c *Conn) writeDeadline() time.Time {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	return c.deadline
+}
+
+// SetPingHandler sets the handler for ping frames. The default handler replies
+// with a pong carrying the same application data.
+func (c *Conn) SetPingHandler(h func(appData string) error) {
+	if h == nil {
+		h = func(appData string) error {
+			err := c.WriteControl(PongMessage, []byte(appData), time.Now().Add(controlWriteTimeout))
+			if err == ErrCloseSent {
+				return nil
+			}
+			return err
+		}
+	}
+	c.pingHandler = h
+}
+
+func (c *Conn) SetPongHandler(h func(appData string) error) {
+	if h == nil {
+		h = func(string) error { return nil }
+	}
+	c.pongHandler = h
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func isControl(opcode int) bool {
+	return opcode >= CloseMessage
+}
+
+func isValidCloseCode(code int) bool {
+	switch code {
+	case CloseNoStatusReceived, CloseAbnormalClosure, CloseTLSHandshake:
+		return false
+	}
+	return (code >= CloseNormalClosure && code <= CloseInternalServerErr && code != 1004) || (code >= 3000 && code <= 4999)
+}
+
+func (c *Conn) readFrame(received int64) (bool, int, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := int(header[0] & 0x0f)
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, &protocolError{CloseProtocolError, "unexpected reserved bits"}
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, &protocolError{CloseProtocolError, "client frame is not masked"}
+	}
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size := binary.BigEndian.Uint64(ext[:])
+		if size>>63 != 0 {
+			return false, 0, nil, &protocolError{CloseProtocolError, "invalid frame length"}
+		}
+		length = int64(size)
+	}
+	if isControl(opcode) {
+		if !fin {
+			return false, 0, nil, &protocolError{CloseProtocolError, "fragmented control frame"}
+		}
+		if length > maxControlPayloadSize {
+			return false, 0, nil, &protocolError{CloseProtocolError, "control frame too large"}
+		}
+	} else if length > c.readLimit-received {
+		return false, 0, nil, ErrReadLimit
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i&3]
+	}
+	return fin, opcode, payload, nil
+}
+
+// failRead records a fatal read error and tells the peer why the connection
+// is being closed.
+func (c *Conn) failRead(err error) error {
+	switch e := err.(type) {
+	case *protocolError:
+		c.WriteClose(e.code, e.msg)
+	default:
+		if err == ErrReadLimit {
+			c.WriteClose(CloseMessageTooBig, "")
+		}
+	}
+	c.readErr = err
+	return err
+}
+
+func (c *Conn) handleClose(payload []byte) error {
+	if len(payload) == 0 {
+		c.WriteClose(CloseNoStatusReceived, "")
+		return &CloseError{Code: CloseNoStatusReceived}
+	}
+	if len(payload) < 2 {
+		return &protocolError{CloseProtocolError, "invalid close payload"}
+	}
+	code := int(binary.BigEndian.Uint16(payload))
+	text := payload[2:]
+	if !isValidCloseCode(code) {
+		return &protocolError{CloseProtocolError, "invalid close code"}
+	}
+	if !utf8.Valid(text) {
+		return &protocolError{CloseInvalidFramePayloadData, "invalid utf8 close reason"}
+	}
+	c.WriteClose(code, "")
+	return &CloseError{Code: code, Text: string(text)}
+}
+
+// ReadMessage reads the next complete data message, reassembling fragments
+// and dispatching control frames received in between.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+	messageType := 0
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame(int64(len(message)))
+		if err != nil {
+			return 0, nil, c.failRead(err)
+		}
+		switch opcode {
+		case PingMessage:
+			if err := c.pingHandler(string(payload)); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if err := c.pongHandler(string(payload)); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case CloseMessage:
+			err := c.handleClose(payload)
+			if _, ok := err.(*CloseError); ok {
+				c.readErr = err
+				return 0, nil, err
+			}
+			return 0, nil, c.failRead(err)
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.failRead(&protocolError{CloseProtocolError, "expected continuation frame"})
+			}
+			messageType = opcode
+			message = payload
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.failRead(&protocolError{CloseProtocolError, "unexpected continuation frame"})
+			}
+			message = append(message, payload...)
+		default:
+			return 0, nil, c.failRead(&protocolError{CloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode)})
+		}
+		if fin {
+			break
+		}
+	}
+	if messageType == TextMessage && !utf8.Valid(message) {
+		return 0, nil, c.failRead(&protocolError{CloseInvalidFramePayloadData, "invalid utf8 payload"})
+	}
+	return messageType, message, nil
+}
+
+// writeFrame sends one frame. A non-zero deadline applies to this frame only,
+// if it is earlier than the deadline set with SetWriteDeadline.
+func (c *Conn) writeFrame(fin bool, opcode int, data []byte, deadline time.Time) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	if current := c.writeDeadline(); !deadline.IsZero() && (current.IsZero() || deadline.Before(current)) {
+		c.conn.SetWriteDeadline(deadline)
+		defer func() {
+			c.conn.SetWriteDeadline(c.writeDeadline())
+		}()
+	}
+	frame := make([]byte, 0, len(data)+10)
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	frame = append(frame, b0)
+	length := len(data)
+	switch {
+	case length <= 125:
+		frame = append(frame, byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 126, byte(length>>8), byte(length))
+	default:
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(length))
+		frame = append(frame, 127)
+		frame = append(frame, ext[:]...)
+	}
+	frame = append(frame, data...)
+	if opcode == CloseMessage {
+		c.closeSent = true
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if isControl(messageType) {
+		return c.WriteControl(messageType, data, time.Time{})
+	}
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: unknown message type %d", messageType)
+	}
+	return c.writeFrame(true, messageType, data, time.Time{})
+}
+
+func (c *Conn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	if !isControl(messageType) || messageType > PongMessage {
+		return fmt.Errorf("websocket: unknown control message type %d", messageType)
+	}
+	if len(data) > maxControlPayloadSize {
+		return errors.New("websocket: control frame too large")
+	}
+	return c.writeFrame(true, messageType, data, deadline)
+}
+
+func (c *Conn) WriteClose(code int, text string) error {
+	var payload []byte
+	if code != CloseNoStatusReceived {
+		payload = make([]byte, 2, 2+len(text))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, text...)
+		if len(payload) > maxControlPayloadSize {
+			payload = payload[:maxControlPayloadSize]
+		}
+	}
+	return c.WriteControl(CloseMessage, payload, time.Now().Add(controlWriteTimeout))
+}
+
+type messageWriter struct {
+	c       *Conn
+	opcode  int
+	started bool
+	closed  bool
+}
+
+// NextWriter returns a writer for a fragmented message. Every call to Write
+// sends one frame and Close sends the final frame. Control frames may be
+// written concurrently, but only one message writer may be used at a time.
+func (c *Conn) NextWriter(messageType int) (io.WriteCloser, error) {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return nil, fmt.Errorf("websocket: unknown message type %d", messageType)
+	}
+	return &messageWriter{c: c, opcode: messageType}, nil
+}
+
+func (w *messageWriter) frameOpcode() int {
+	if w.started {
+		return continuationFrame
+	}
+	w.started = true
+	return w.opcode
+}
+
+func (w *messageWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("websocket: write to closed writer")
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := w.c.writeFrame(false, w.frameOpcode(), p, time.Time{}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *messageWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.c.writeFrame(true, w.frameOpcode(), nil, time.Time{})
+}
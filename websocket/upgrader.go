@@ -0,0 +1,161 @@
+package websocket
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	ErrBadHandshake   = errors.New("websocket: bad handshake")
+	ErrOriginNotAllow = errors.New("websocket: request origin not allowed")
+	ErrNotHijacker    = errors.New("websocket: response does not implement http.Hijacker")
+)
+
+type Upgrader struct {
+	// ReadLimit is the maximum size in bytes of a message read from the peer.
+	// Zero means DefaultReadLimit.
+	ReadLimit int64
+
+	// HandshakeTimeout is the time allowed to write the handshake response.
+	HandshakeTimeout time.Duration
+
+	// Subprotocols lists the supported protocols in order of preference.
+	Subprotocols []string
+
+	// CheckOrigin returns true if the request Origin header is acceptable.
+	// When nil, only requests without Origin or with an Origin whose host
+	// equals the Host header are accepted.
+	CheckOrigin func(r *http.Request) bool
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func computeAcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key))
+	h.Write([]byte(acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (u *Upgrader) selectSubprotocol(r *http.Request) string {
+	for _, server := range u.Subprotocols {
+		for _, value := range r.Header["Sec-Websocket-Protocol"] {
+			for _, client := range strings.Split(value, ",") {
+				if strings.TrimSpace(client) == server {
+					return server
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func (u *Upgrader) fail(w http.ResponseWriter, code int, err error) (*Conn, error) {
+	w.Header().Set("Sec-Websocket-Version", "13")
+	http.Error(w, http.StatusText(code), code)
+	return nil, err
+}
+
+// Upgrade performs the server side of the opening handshake and takes over
+// the underlying connection. On failure an HTTP error response is written.
+func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (*Conn, error) {
+	if r.Method != "GET" {
+		return u.fail(w, http.StatusMethodNotAllowed, ErrBadHandshake)
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return u.fail(w, http.StatusBadRequest, ErrBadHandshake)
+	}
+	if r.Header.Get("Sec-Websocket-Version") != "13" {
+		return u.fail(w, http.StatusUpgradeRequired, ErrBadHandshake)
+	}
+	key := r.Header.Get("Sec-Websocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return u.fail(w, http.StatusBadRequest, ErrBadHandshake)
+	}
+	checkOrigin := u.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = checkSameOrigin
+	}
+	if !checkOrigin(r) {
+		return u.fail(w, http.StatusForbidden, ErrOriginNotAllow)
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return u.fail(w, http.StatusInternalServerError, ErrNotHijacker)
+	}
+	netConn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	if brw.Reader.Buffered() > 0 {
+		netConn.Close()
+		return nil, errors.New("websocket: client sent data before handshake is complete")
+	}
+
+	subprotocol := u.selectSubprotocol(r)
+	buf := []byte("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+	buf = append(buf, computeAcceptKey(key)...)
+	buf = append(buf, "\r\n"...)
+	if subprotocol != "" {
+		buf = append(buf, "Sec-WebSocket-Protocol: "...)
+		buf = append(buf, subprotocol...)
+		buf = append(buf, "\r\n"...)
+	}
+	for name, values := range responseHeader {
+		if strings.EqualFold(name, "Sec-Websocket-Protocol") {
+			continue
+		}
+		for _, value := range values {
+			buf = append(buf, name...)
+			buf = append(buf, ": "...)
+			buf = append(buf, strings.NewReplacer("\r", "", "\n", "").Replace(value)...)
+			buf = append(buf, "\r\n"...)
+		}
+	}
+	buf = append(buf, "\r\n"...)
+
+	if u.HandshakeTimeout > 0 {
+		netConn.SetWriteDeadline(time.Now().Add(u.HandshakeTimeout))
+	}
+	if _, err := netConn.Write(buf); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if u.HandshakeTimeout > 0 {
+		netConn.SetWriteDeadline(time.Time{})
+	}
+
+	conn := newConn(netConn, brw.Reader, subprotocol)
+	if u.ReadLimit > 0 {
+		conn.SetReadLimit(u.ReadLimit)
+	}
+	return conn, nil
+}
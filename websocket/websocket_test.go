@@ -0,0 +1,356 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+type testClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+	resp *http.Response
+}
+
+func dial(t *testing.T, server *httptest.Server, header http.Header) *testClient {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Dial got error: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req, _ := http.NewRequest("GET", server.URL+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", testKey)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Write(conn)
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatalf("Read handshake response got error: %v", err)
+	}
+	return &testClient{conn: conn, br: br, resp: resp}
+}
+
+func (tc *testClient) writeFrame(fin bool, opcode int, data []byte, masked bool) {
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+	b1 := byte(0)
+	if masked {
+		b1 = 0x80
+	}
+	switch {
+	case len(data) <= 125:
+		frame = append(frame, b1|byte(len(data)))
+	case len(data) <= 0xffff:
+		frame = append(frame, b1|126, byte(len(data)>>8), byte(len(data)))
+	default:
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(len(data)))
+		frame = append(frame, b1|127)
+		frame = append(frame, ext[:]...)
+	}
+	payload := append([]byte{}, data...)
+	if masked {
+		mask := []byte{0x12, 0x34, 0x56, 0x78}
+		frame = append(frame, mask...)
+		for i := range payload {
+			payload[i] ^= mask[i&3]
+		}
+	}
+	tc.conn.Write(append(frame, payload...))
+}
+
+func (tc *testClient) readFrame(t *testing.T) (bool, int, []byte) {
+	var header [2]byte
+	if _, err := io.ReadFull(tc.br, header[:]); err != nil {
+		t.Fatalf("Read frame got error: %v", err)
+	}
+	if header[1]&0x80 != 0 {
+		t.Fatalf("Server frame should not be masked")
+	}
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(tc.br, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	} else if length == 127 {
+		var ext [8]byte
+		io.ReadFull(tc.br, ext[:])
+		length = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload := make([]byte, length)
+	io.ReadFull(tc.br, payload)
+	return header[0]&0x80 != 0, int(header[0] & 0x0f), payload
+}
+
+func (tc *testClient) expectClose(t *testing.T, code int) {
+	_, opcode, payload := tc.readFrame(t)
+	if opcode != CloseMessage {
+		t.Fatalf("Expect close frame but got opcode %d", opcode)
+	}
+	if len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != code {
+		t.Fatalf("Expect close code %d but got payload %v", code, payload)
+	}
+}
+
+func newServer(upgrader *Upgrader, handler func(conn *Conn)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}))
+}
+
+func echoHandler(conn *Conn) {
+	for {
+		mt, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.WriteMessage(mt, data)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	server := newServer(&Upgrader{Subprotocols: []string{"chat"}}, echoHandler)
+	defer server.Close()
+	tc := dial(t, server, http.Header{"Sec-Websocket-Protocol": {"superchat, chat"}})
+	defer tc.conn.Close()
+	if tc.resp.StatusCode != 101 {
+		t.Fatalf("Expect 101 but got %d", tc.resp.StatusCode)
+	}
+	if accept := tc.resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Accept key not correct: %s", accept)
+	}
+	if proto := tc.resp.Header.Get("Sec-WebSocket-Protocol"); proto != "chat" {
+		t.Fatalf("Subprotocol not correct: %s", proto)
+	}
+}
+
+func TestHandshakeErrors(t *testing.T) {
+	server := newServer(&Upgrader{}, echoHandler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request got error: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Fatalf("Expect 400 but got %d", resp.StatusCode)
+	}
+
+	tc := dial(t, server, http.Header{"Sec-Websocket-Version": {"8"}})
+	if tc.resp.StatusCode != 426 {
+		t.Fatalf("Expect 426 but got %d", tc.resp.StatusCode)
+	}
+
+	tc = dial(t, server, http.Header{"Origin": {"http://evil.example.com"}})
+	if tc.resp.StatusCode != 403 {
+		t.Fatalf("Expect 403 but got %d", tc.resp.StatusCode)
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	upgrader := &Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return r.Header.Get("Origin") == "https://app.example.com"
+		},
+	}
+	server := newServer(upgrader, echoHandler)
+	defer server.Close()
+	tc := dial(t, server, http.Header{"Origin": {"https://app.example.com"}})
+	defer tc.conn.Close()
+	if tc.resp.StatusCode != 101 {
+		t.Fatalf("Expect 101 but got %d", tc.resp.StatusCode)
+	}
+}
+
+func TestEchoMessages(t *testing.T) {
+	server := newServer(&Upgrader{}, echoHandler)
+	defer server.Close()
+	tc := dial(t, server, nil)
+	defer tc.conn.Close()
+
+	tc.writeFrame(true, TextMessage, []byte("hello"), true)
+	fin, opcode, payload := tc.readFrame(t)
+	if !fin || opcode != TextMessage || string(payload) != "hello" {
+		t.Fatalf("Echo text not correct: %v %d %q", fin, opcode, payload)
+	}
+
+	large := []byte(strings.Repeat("x", 70000))
+	tc.writeFrame(true, BinaryMessage, large, true)
+	_, opcode, payload = tc.readFrame(t)
+	if opcode != BinaryMessage || string(payload) != string(large) {
+		t.Fatalf("Echo binary not correct: %d %d", opcode, len(payload))
+	}
+}
+
+func TestFragmentedMessageWithPing(t *testing.T) {
+	server := newServer(&Upgrader{}, echoHandler)
+	defer server.Close()
+	tc := dial(t, server, nil)
+	defer tc.conn.Close()
+
+	tc.writeFrame(false, TextMessage, []byte("hel"), true)
+	tc.writeFrame(true, PingMessage, []byte("ping"), true)
+	tc.writeFrame(true, continuationFrame, []byte("lo"), true)
+
+	_, opcode, payload := tc.readFrame(t)
+	if opcode != PongMessage || string(payload) != "ping" {
+		t.Fatalf("Pong not correct: %d %q", opcode, payload)
+	}
+	_, opcode, payload = tc.readFrame(t)
+	if opcode != TextMessage || string(payload) != "hello" {
+		t.Fatalf("Reassembled message not correct: %d %q", opcode, payload)
+	}
+}
+
+func TestCloseHandshake(t *testing.T) {
+	result := make(chan error, 1)
+	server := newServer(&Upgrader{}, func(conn *Conn) {
+		_, _, err := conn.ReadMessage()
+		result <- err
+	})
+	defer server.Close()
+	tc := dial(t, server, nil)
+	defer tc.conn.Close()
+
+	tc.writeFrame(true, CloseMessage, []byte{0x03, 0xe8, 'b', 'y', 'e'}, true)
+	tc.expectClose(t, CloseNormalClosure)
+	err := <-result
+	ce, ok := err.(*CloseError)
+	if !ok || ce.Code != CloseNormalClosure || ce.Text != "bye" {
+		t.Fatalf("Close error not correct: %v", err)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		send     func(tc *testClient)
+		code     int
+		upgrader *Upgrader
+	}{
+		{"unmasked", func(tc *testClient) { tc.writeFrame(true, TextMessage, []byte("hi"), false) }, CloseProtocolError, nil},
+		{"bad continuation", func(tc *testClient) { tc.writeFrame(true, continuationFrame, []byte("hi"), true) }, CloseProtocolError, nil},
+		{"fragmented ping", func(tc *testClient) { tc.writeFrame(false, PingMessage, []byte("hi"), true) }, CloseProtocolError, nil},
+		{"unknown opcode", func(tc *testClient) { tc.writeFrame(true, 3, []byte("hi"), true) }, CloseProtocolError, nil},
+		{"invalid utf8", func(tc *testClient) { tc.writeFrame(true, TextMessage, []byte{0xff, 0xfe}, true) }, CloseInvalidFramePayloadData, nil},
+		{"read limit", func(tc *testClient) {
+			tc.writeFrame(false, BinaryMessage, []byte("12345"), true)
+			tc.writeFrame(true, continuationFrame, []byte("67890"), true)
+		}, CloseMessageTooBig, &Upgrader{ReadLimit: 8}},
+	}
+	for _, tcase := range cases {
+		upgrader := tcase.upgrader
+		if upgrader == nil {
+			upgrader = &Upgrader{}
+		}
+		server := newServer(upgrader, echoHandler)
+		tc := dial(t, server, nil)
+		tcase.send(tc)
+		t.Run(tcase.name, func(t *testing.T) {
+			tc.expectClose(t, tcase.code)
+		})
+		tc.conn.Close()
+		server.Close()
+	}
+}
+
+func TestNextWriter(t *testing.T) {
+	server := newServer(&Upgrader{}, func(conn *Conn) {
+		w, _ := conn.NextWriter(TextMessage)
+		fmt.Fprint(w, "frag")
+		fmt.Fprint(w, "ment")
+		w.Close()
+		conn.ReadMessage()
+	})
+	defer server.Close()
+	tc := dial(t, server, nil)
+	defer tc.conn.Close()
+
+	expects := []struct {
+		fin     bool
+		opcode  int
+		payload string
+	}{
+		{false, TextMessage, "frag"},
+		{false, continuationFrame, "ment"},
+		{true, continuationFrame, ""},
+	}
+	for _, expect := range expects {
+		fin, opcode, payload := tc.readFrame(t)
+		if fin != expect.fin || opcode != expect.opcode || string(payload) != expect.payload {
+			t.Fatalf("Frame not correct: %v %d %q", fin, opcode, payload)
+		}
+	}
+}
+
+func TestNonPositiveReadLimit(t *testing.T) {
+	server := newServer(&Upgrader{}, func(conn *Conn) {
+		conn.SetReadLimit(0)
+		echoHandler(conn)
+	})
+	defer server.Close()
+	tc := dial(t, server, nil)
+	defer tc.conn.Close()
+	frame := []byte{0x80 | BinaryMessage, 0x80 | 127, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 1, 2, 3, 4}
+	tc.conn.Write(frame)
+	tc.expectClose(t, CloseMessageTooBig)
+}
+
+type deadlineConn struct {
+	net.Conn
+	deadlines []time.Time
+}
+
+func (c *deadlineConn) SetWriteDeadline(t time.Time) error {
+	c.deadlines = append(c.deadlines, t)
+	return nil
+}
+
+func (c *deadlineConn) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestWriteControlKeepsWriteDeadline(t *testing.T) {
+	dc := &deadlineConn{}
+	conn := newConn(dc, bufio.NewReader(strings.NewReader("")), "")
+	user := time.Now().Add(time.Hour)
+	conn.SetWriteDeadline(user)
+	control := time.Now().Add(time.Second)
+	if err := conn.WriteControl(PingMessage, nil, control); err != nil {
+		t.Fatalf("WriteControl failed: %v", err)
+	}
+	if len(dc.deadlines) != 3 || !dc.deadlines[1].Equal(control) || !dc.deadlines[2].Equal(user) {
+		t.Fatalf("Deadlines not correct: %v", dc.deadlines)
+	}
+
+	dc.deadlines = nil
+	conn.SetWriteDeadline(time.Now().Add(time.Millisecond))
+	if err := conn.WriteControl(PingMessage, nil, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("WriteControl failed: %v", err)
+	}
+	if len(dc.deadlines) != 1 {
+		t.Fatalf("Later control deadline should not override: %v", dc.deadlines)
+	}
+}
@@ -0,0 +1,63 @@
+package tgin
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blacktear23/tgin/websocket"
+)
+
+func TestUpgradeWebSocket(t *testing.T) {
+	r := NewRouteGroup()
+	status := make(chan int, 1)
+	r.Use(func(c *Context) {
+		c.Next()
+		status <- c.ResponseWriter().Status()
+	})
+	r.Get("/ws", func(c *Context) {
+		conn, err := c.UpgradeWebSocket()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("hi"))
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	assertNil(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req, _ := http.NewRequest("GET", server.URL+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Write(conn)
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	assertNil(t, err)
+	assertEqual(t, 101, resp.StatusCode)
+	frame := make([]byte, 4)
+	_, err = io.ReadFull(br, frame)
+	assertNil(t, err)
+	assertEqual(t, []byte{0x81, 2, 'h', 'i'}, frame)
+	assertEqual(t, 101, <-status)
+}
+
+func TestUpgradeWebSocketBadRequest(t *testing.T) {
+	r := NewRouteGroup()
+	r.Get("/ws", func(c *Context) {
+		_, err := c.UpgradeWebSocket()
+		assertNotNil(t, err)
+	})
+	resp := processRequest(r, "GET", "/ws")
+	assertEqual(t, 400, resp.StatusCode)
+}